@@ -0,0 +1,138 @@
+package koanf
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/knadh/koanf/maps"
+)
+
+// AliasConflict is the policy for a config map being merged that has both
+// a deprecated key (see Conf.Aliases) and the key that replaces it.
+type AliasConflict int
+
+const (
+	// AliasConflictWarn retains the value of the new key, discards the value
+	// of the deprecated key, and reports the conflict to Conf.OnDeprecated.
+	AliasConflictWarn AliasConflict = iota
+
+	// AliasConflictError rejects the merge with an error.
+	AliasConflictError
+)
+
+// Deprecation is a deprecated key in a config map being merged that was
+// moved to the key that replaces it. See Conf.Aliases.
+type Deprecation struct {
+	// Key is the deprecated key path, for instance, `db.addr`.
+	Key string
+
+	// NewKey is the key path that replaces it, for instance, `database.host`.
+	NewKey string
+
+	// Conflict indicates that the map had the new key as well, whose
+	// value was retained, and the value of the deprecated key was discarded.
+	Conflict bool
+}
+
+// moveAliases returns the config map c with the values of the deprecated keys
+// in Conf.Aliases moved to their new keys, and the deprecations. If there are
+// deprecated keys, c is copied and not modified.
+func (ko *Koanf) moveAliases(c map[string]any) (map[string]any, []Deprecation, error) {
+	var (
+		delim = ko.conf.Delim
+		fold  = ko.conf.CaseInsensitive
+		keys  = make([]string, 0, len(ko.conf.Aliases))
+	)
+	for k := range ko.conf.Aliases {
+		if _, ok := findPath(c, maps.SplitKey(k, delim), fold); ok {
+			keys = append(keys, k)
+		}
+	}
+	if len(keys) == 0 {
+		return c, nil, nil
+	}
+	sort.Strings(keys)
+
+	c = maps.Copy(c)
+	out := make([]Deprecation, 0, len(keys))
+	for _, k := range keys {
+		path, ok := findPath(c, maps.SplitKey(k, delim), fold)
+		if !ok {
+			continue
+		}
+
+		var (
+			d     = Deprecation{Key: k, NewKey: ko.conf.Aliases[k]}
+			v     = maps.Search(c, path)
+			parts = maps.SplitKey(d.NewKey, delim)
+		)
+		if _, ok := findPath(c, parts, fold); ok {
+			if ko.conf.AliasConflict == AliasConflictError {
+				return nil, nil, fmt.Errorf("config has both the deprecated key %s and %s that replaces it", k, d.NewKey)
+			}
+			d.Conflict = true
+		}
+
+		maps.Delete(c, path)
+		if !d.Conflict {
+			setPath(c, parts, v, fold)
+		}
+		out = append(out, d)
+	}
+
+	return c, out, nil
+}
+
+// findPath returns the path of the nested map keys in mp that match the key
+// parts, case-insensitively if fold is set, and whether there's such a key.
+func findPath(mp map[string]any, parts []string, fold bool) ([]string, bool) {
+	out := make([]string, 0, len(parts))
+	for i, p := range parts {
+		k, ok := findKey(mp, p, fold)
+		if !ok {
+			return nil, false
+		}
+		out = append(out, k)
+
+		if i == len(parts)-1 {
+			break
+		}
+		if mp, ok = mp[k].(map[string]any); !ok {
+			return nil, false
+		}
+	}
+	return out, len(out) > 0
+}
+
+// setPath sets the value at the key parts in mp, creating the nested maps
+// on the path and using the existing keys that match the parts
+// case-insensitively if fold is set.
+func setPath(mp map[string]any, parts []string, v any, fold bool) {
+	for i, p := range parts {
+		k, ok := findKey(mp, p, fold)
+		if !ok {
+			k = p
+		}
+		if i == len(parts)-1 {
+			mp[k] = v
+			return
+		}
+
+		next, ok := mp[k].(map[string]any)
+		if !ok {
+			next = make(map[string]any)
+			mp[k] = next
+		}
+		mp = next
+	}
+}
+
+// findKey returns the key in mp that matches the key, case-insensitively if
+// fold is set.
+func findKey(mp map[string]any, key string, fold bool) (string, bool) {
+	if fold {
+		return lookupKey(mp, key)
+	}
+	_, ok := mp[key]
+	return key, ok
+}
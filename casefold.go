@@ -0,0 +1,129 @@
+package koanf
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/knadh/koanf/maps"
+)
+
+// flatten updates the flattened config map and the key maps of an
+// unpublished state from its config map.
+func (ko *Koanf) flatten(st *state) {
+	st.confMapFlat, st.keyMap = maps.Flatten(st.confMap, nil, ko.conf.Delim)
+	st.keyMap = populateKeyParts(st.keyMap, ko.conf.Delim)
+
+	// Providers like etcd and Consul return flat keys that contain the
+	// delimiter at the top level without nesting them.
+	st.unquotedKeys = nil
+	for k, parts := range st.keyMap {
+		if ko.conf.Delim == "" || !strings.Contains(parts[0], ko.conf.Delim) {
+			continue
+		}
+		rest := strings.Join(parts[1:], ko.conf.Delim)
+		if rest != maps.JoinKey(parts[1:], ko.conf.Delim) {
+			continue
+		}
+		uk := strings.Join(parts, ko.conf.Delim)
+		if ko.conf.CaseInsensitive {
+			uk = strings.ToLower(uk)
+		}
+
+		// Of the keys that are the same without quotes, the first
+		// in the sort order is used.
+		if prev, ok := st.unquotedKeys[uk]; ok && prev < k {
+			continue
+		}
+		if st.unquotedKeys == nil {
+			st.unquotedKeys = make(map[string]string)
+		}
+		st.unquotedKeys[uk] = k
+	}
+
+	if !ko.conf.CaseInsensitive {
+		return
+	}
+
+	st.foldedKeys = make(map[string]string, len(st.keyMap))
+	for k := range st.keyMap {
+		st.foldedKeys[strings.ToLower(k)] = k
+	}
+}
+
+// key returns the key path as it is in the config map. If Conf.CaseInsensitive
+// is set, it's the path of the existing key that matches the given path
+// case-insensitively in the state. If there's no such key, but there's a key
+// whose top-level part contains the delimiter that matches the path without
+// quotes, like the flat keys that some providers return without nesting them
+// (eg: `parent.name` for `{"parent.name": 1}`), it's that key. Otherwise,
+// it's path itself.
+func (ko *Koanf) key(st *state, path string) string {
+	// Normalize indexes, eg: `a[0].b` to `a.0.b`.
+	if strings.Contains(path, "[") {
+		path = normalizeIndexes(path, ko.conf.Delim)
+	}
+
+	// Normalize the quoting of key parts, eg: `"a".b` to `a.b`.
+	if strings.Contains(path, `"`) {
+		path = maps.JoinKey(maps.SplitKey(path, ko.conf.Delim), ko.conf.Delim)
+	}
+
+	uk := path
+	if ko.conf.CaseInsensitive {
+		uk = strings.ToLower(path)
+		if k, ok := st.foldedKeys[uk]; ok {
+			return k
+		}
+	} else if _, ok := st.keyMap[path]; ok {
+		return path
+	}
+
+	if k, ok := st.unquotedKeys[uk]; ok {
+		return k
+	}
+	return path
+}
+
+// foldCase returns a copy of the config map src with its keys, recursively,
+// renamed to the casing of the keys in dest that they match case-insensitively.
+// Keys in src that differ only in case are merged into one, the first of them
+// in the sort order deciding the casing.
+func foldCase(src, dest map[string]any) map[string]any {
+	names := make(map[string]string, len(dest)+len(src))
+	for k := range dest {
+		names[strings.ToLower(k)] = k
+	}
+
+	keys := make([]string, 0, len(src))
+	for k := range src {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	out := make(map[string]any, len(src))
+	for _, k := range keys {
+		lk := strings.ToLower(k)
+		name, ok := names[lk]
+		if !ok {
+			name = k
+			names[lk] = k
+		}
+
+		v := src[k]
+		if sub, ok := v.(map[string]any); ok {
+			d, _ := dest[name].(map[string]any)
+			sub = foldCase(sub, d)
+
+			// A map with the same name (in a different case) in src.
+			if prev, ok := out[name].(map[string]any); ok {
+				maps.Merge(foldCase(sub, prev), prev)
+				continue
+			}
+			v = sub
+		}
+
+		out[name] = v
+	}
+
+	return out
+}
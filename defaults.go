@@ -0,0 +1,329 @@
+package koanf
+
+import (
+	"encoding"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/knadh/koanf/maps"
+)
+
+var textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+
+// defaulter fills in the values in the default tags of struct fields
+// whose keys are absent from a config map.
+type defaulter struct {
+	tag, defTag, delim string
+	squash, flat       bool
+
+	// visiting holds the struct types being walked without a config value
+	// to stop recursive types from being descended into endlessly.
+	visiting map[reflect.Type]bool
+}
+
+// defaultsProvider implements a Provider that reads the default
+// values in the tags of a struct.
+type defaultsProvider struct {
+	o     any
+	delim string
+	conf  UnmarshalConf
+}
+
+// Defaults returns a Provider that reads the values in the default tags
+// (see UnmarshalConf.Defaults) of the fields of the struct o as a config map.
+// Loading it before the other sources makes the defaults visible to Get,
+// Exists, Keys etc. and not just to UnmarshalWithConf. The Tag, DefaultTag,
+// FlatPaths, and DecoderConfig.Squash options in c are used to read the tags.
+//
+// ko.Load(koanf.Defaults(Config{}, ".", koanf.UnmarshalConf{}), nil)
+func Defaults(o any, delim string, c UnmarshalConf) Provider {
+	return &defaultsProvider{o: o, delim: delim, conf: c}
+}
+
+// ReadBytes is not supported by the defaults provider.
+func (d *defaultsProvider) ReadBytes() ([]byte, error) {
+	return nil, errors.New("defaults provider does not support this method")
+}
+
+// Read returns the default values of the struct's fields as a nested config map.
+func (d *defaultsProvider) Read() (map[string]any, error) {
+	v, err := newDefaulter(d.conf, d.delim).fill(nil, reflect.TypeOf(d.o), "")
+	if err != nil {
+		return nil, err
+	}
+
+	mp, _ := v.(map[string]any)
+	if mp == nil {
+		return map[string]any{}, nil
+	}
+	if d.conf.FlatPaths {
+		return maps.Unflatten(mp, d.delim), nil
+	}
+	return mp, nil
+}
+
+func newDefaulter(c UnmarshalConf, delim string) *defaulter {
+	d := &defaulter{
+		tag:      c.Tag,
+		defTag:   c.DefaultTag,
+		delim:    delim,
+		flat:     c.FlatPaths,
+		visiting: make(map[reflect.Type]bool),
+	}
+	if d.tag == "" {
+		d.tag = "koanf"
+	}
+	if d.defTag == "" {
+		d.defTag = "default"
+	}
+	if c.DecoderConfig != nil {
+		d.squash = c.DecoderConfig.Squash
+	}
+	return d
+}
+
+// fill returns the config value v that's to be unmarshalled into the type t
+// with the defaults of the absent struct fields filled in. v is not modified.
+// key is the full key path of v. A nil v with no defaults returns nil.
+func (d *defaulter) fill(v any, t reflect.Type, key string) (any, error) {
+	if t == nil {
+		return v, nil
+	}
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	// Types that are unmarshalled from text are values and not structs.
+	if isText(t) {
+		return v, nil
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		mp, ok := v.(map[string]any)
+		if v != nil && !ok {
+			return v, nil
+		}
+
+		out := make(map[string]any, len(mp))
+		for k, val := range mp {
+			out[k] = val
+		}
+		if err := d.fillStruct(out, t, key); err != nil {
+			return nil, err
+		}
+		if v == nil && len(out) == 0 {
+			return nil, nil
+		}
+		return out, nil
+
+	case reflect.Slice, reflect.Array:
+		sl, ok := v.([]any)
+		if !ok {
+			return v, nil
+		}
+		out := make([]any, len(sl))
+		for i, val := range sl {
+			val, err := d.fill(val, t.Elem(), joinKey(key, strconv.Itoa(i), d.delim))
+			if err != nil {
+				return nil, err
+			}
+			out[i] = val
+		}
+		return out, nil
+
+	case reflect.Map:
+		mp, ok := v.(map[string]any)
+		if !ok {
+			return v, nil
+		}
+		out := make(map[string]any, len(mp))
+		for k, val := range mp {
+			val, err := d.fill(val, t.Elem(), joinKey(key, maps.QuoteKey(k, d.delim), d.delim))
+			if err != nil {
+				return nil, err
+			}
+			out[k] = val
+		}
+		return out, nil
+	}
+
+	return v, nil
+}
+
+// fillStruct fills in the defaults of the fields of the struct type t
+// that are absent from the map mp, and the defaults of nested structs.
+func (d *defaulter) fillStruct(mp map[string]any, t reflect.Type, key string) error {
+	return d.eachField(t, func(name string, f reflect.StructField) error {
+		fkey := joinKey(key, name, d.delim)
+
+		// mapstructure matches keys to field names case-insensitively.
+		if k, ok := lookupKey(mp, name); ok {
+			// With flat paths, the tags hold complete key paths and there is no nesting.
+			if d.flat {
+				return nil
+			}
+			val, err := d.fill(mp[k], f.Type, fkey)
+			if err != nil {
+				return err
+			}
+			mp[k] = val
+			return nil
+		}
+
+		if def, ok := f.Tag.Lookup(d.defTag); ok {
+			val, err := parseDefault(def, f.Type)
+			if err != nil {
+				return fmt.Errorf("invalid default for %s: %w", fkey, err)
+			}
+			mp[name] = val
+			return nil
+		}
+
+		// Pointers to structs absent from the config are left nil and not
+		// allocated only to hold defaults.
+		if d.flat || f.Type.Kind() == reflect.Pointer {
+			return nil
+		}
+		val, err := d.fill(nil, f.Type, fkey)
+		if err != nil {
+			return err
+		}
+		if val != nil {
+			mp[name] = val
+		}
+		return nil
+	})
+}
+
+// eachField calls fn with the key name of every field of the struct type t
+// that's decoded by mapstructure, including the fields of squashed embedded
+// structs, which share the parent's map even if their types are unexported.
+func (d *defaulter) eachField(t reflect.Type, fn func(name string, f reflect.StructField) error) error {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name, opts, _ := strings.Cut(f.Tag.Get(d.tag), ",")
+		if name == "-" || hasTagOpt(opts, "remain") {
+			continue
+		}
+
+		if f.Anonymous && (d.squash || hasTagOpt(opts, "squash")) {
+			ft := f.Type
+			for ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				if err := d.eachField(ft, fn); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+
+		if !f.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		if err := fn(name, f); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// isText checks whether the type t is unmarshalled from text.
+func isText(t reflect.Type) bool {
+	return reflect.PointerTo(t).Implements(textUnmarshalerType)
+}
+
+// lookupKey returns the key in mp that matches name, preferring
+// an exact match over a case-insensitive one.
+func lookupKey(mp map[string]any, name string) (string, bool) {
+	if _, ok := mp[name]; ok {
+		return name, true
+	}
+	for k := range mp {
+		if strings.EqualFold(k, name) {
+			return k, true
+		}
+	}
+	return "", false
+}
+
+// parseDefault parses the value in a default tag into a config value for the
+// type t. Slices are comma separated lists and maps are comma separated
+// key=value pairs. Durations and types that implement encoding.TextUnmarshaler
+// are left as strings to be decoded during unmarshalling.
+func parseDefault(s string, t reflect.Type) (any, error) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	if isText(t) {
+		return s, nil
+	}
+	if t == durationType {
+		if _, err := time.ParseDuration(s); err != nil {
+			return nil, err
+		}
+		return s, nil
+	}
+
+	switch t.Kind() {
+	case reflect.String, reflect.Interface:
+		return s, nil
+
+	case reflect.Bool:
+		return strconv.ParseBool(s)
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.ParseInt(s, 0, t.Bits())
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.ParseUint(s, 0, t.Bits())
+
+	case reflect.Float32, reflect.Float64:
+		return strconv.ParseFloat(s, t.Bits())
+
+	case reflect.Slice, reflect.Array:
+		out := []any{}
+		if s == "" {
+			return out, nil
+		}
+		for _, p := range strings.Split(s, ",") {
+			v, err := parseDefault(strings.TrimSpace(p), t.Elem())
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, v)
+		}
+		return out, nil
+
+	case reflect.Map:
+		out := map[string]any{}
+		if s == "" {
+			return out, nil
+		}
+		for _, p := range strings.Split(s, ",") {
+			k, val, ok := strings.Cut(p, "=")
+			if !ok {
+				return nil, fmt.Errorf("%q is not a key=value pair", p)
+			}
+			v, err := parseDefault(strings.TrimSpace(val), t.Elem())
+			if err != nil {
+				return nil, err
+			}
+			out[strings.TrimSpace(k)] = v
+		}
+		return out, nil
+	}
+
+	return nil, fmt.Errorf("defaults are not supported for %s", t)
+}
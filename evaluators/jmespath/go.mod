@@ -0,0 +1,14 @@
+module github.com/knadh/koanf/evaluators/jmespath
+
+go 1.23.0
+
+require (
+	github.com/jmespath/go-jmespath v0.4.0
+	github.com/stretchr/testify v1.8.4
+)
+
+require (
+	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
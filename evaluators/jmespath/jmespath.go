@@ -0,0 +1,80 @@
+// Package jmespath implements a koanf.Evaluator that evaluates
+// JMESPath (https://jmespath.org) expressions against conf maps.
+package jmespath
+
+import (
+	"sync"
+
+	"github.com/jmespath/go-jmespath"
+)
+
+// JMESPath implements a JMESPath evaluator.
+type JMESPath struct {
+	// Compiled expressions.
+	cache sync.Map
+}
+
+// Evaluator returns a JMESPath evaluator.
+func Evaluator() *JMESPath {
+	return &JMESPath{}
+}
+
+// Eval evaluates the JMESPath expression against the conf map, for instance,
+// "upstreams[?weight > `5`].host". Numeric values in the map are compared as
+// float64 and numbers in the result are float64.
+func (j *JMESPath) Eval(expr string, mp map[string]any) (any, error) {
+	var c *jmespath.JMESPath
+	if v, ok := j.cache.Load(expr); ok {
+		c = v.(*jmespath.JMESPath)
+	} else {
+		var err error
+		if c, err = jmespath.Compile(expr); err != nil {
+			return nil, err
+		}
+		j.cache.Store(expr, c)
+	}
+
+	return c.Search(normalize(mp))
+}
+
+// normalize returns a copy of the value with all numbers converted to float64
+// as JMESPath only compares float64 numbers.
+func normalize(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		out := make(map[string]any, len(v))
+		for k, c := range v {
+			out[k] = normalize(c)
+		}
+		return out
+	case []any:
+		out := make([]any, len(v))
+		for i, c := range v {
+			out[i] = normalize(c)
+		}
+		return out
+	case int:
+		return float64(v)
+	case int8:
+		return float64(v)
+	case int16:
+		return float64(v)
+	case int32:
+		return float64(v)
+	case int64:
+		return float64(v)
+	case uint:
+		return float64(v)
+	case uint8:
+		return float64(v)
+	case uint16:
+		return float64(v)
+	case uint32:
+		return float64(v)
+	case uint64:
+		return float64(v)
+	case float32:
+		return float64(v)
+	}
+	return v
+}
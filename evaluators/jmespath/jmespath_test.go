@@ -0,0 +1,45 @@
+package jmespath
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEval(t *testing.T) {
+	mp := map[string]any{
+		"upstreams": []any{
+			map[string]any{"host": "a", "weight": 10},
+			map[string]any{"host": "b", "weight": int64(2)},
+			map[string]any{"host": "c", "weight": 7.5},
+		},
+		"db": map[string]any{"host": "localhost", "port": uint16(5432)},
+	}
+
+	e := Evaluator()
+	for _, c := range []struct {
+		expr string
+		out  any
+	}{
+		{"upstreams[?weight > `5`].host", []any{"a", "c"}},
+		{"upstreams[0].weight", float64(10)},
+		{"db", map[string]any{"host": "localhost", "port": float64(5432)}},
+		{"max_by(upstreams, &weight).host", "a"},
+		{"length(upstreams)", float64(3)},
+		{"nope", nil},
+	} {
+		// Twice to use the compiled expression.
+		for i := 0; i < 2; i++ {
+			out, err := e.Eval(c.expr, mp)
+			require.NoError(t, err, c.expr)
+			assert.Equal(t, c.out, out, c.expr)
+		}
+	}
+
+	// The map isn't modified.
+	assert.Equal(t, 10, mp["upstreams"].([]any)[0].(map[string]any)["weight"])
+
+	_, err := e.Eval("upstreams[?", mp)
+	assert.Error(t, err)
+}
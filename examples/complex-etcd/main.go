@@ -264,14 +264,14 @@ func main() {
 
 	changedC := make(chan string, 1)
 
-	provider.Watch(func(event any, err error) {
+	provider.Watch(context.Background(), func(event koanf.Event, err error) {
 		if err != nil {
 			fmt.Printf("Unexpected error: %v", err)
 			return
 		}
 
 		kCheck.Load(provider, nil)
-		changedC <- kCheck.String(string(event.(*clientv3.Event).Kv.Key))
+		changedC <- kCheck.String(event.Key)
 	})
 
 	var newVal string = "Brian"
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"log"
 	"os"
 
@@ -31,7 +32,7 @@ func main() {
 	k.Print()
 
 	// Watch for all configuration updates.
-	provider.Watch(func(event any, err error) {
+	provider.Watch(context.Background(), func(event koanf.Event, err error) {
 		if err != nil {
 			log.Printf("watch error: %v", err)
 			return
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"os/exec"
@@ -286,7 +287,7 @@ func main() {
 
 	changedC := make(chan string, 1)
 
-	provider.Watch(func(event any, err error) {
+	provider.Watch(context.Background(), func(event koanf.Event, err error) {
 		if err != nil {
 			fmt.Printf("Unexpected error: %v", err)
 			return
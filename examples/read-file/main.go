@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"log"
 
@@ -28,8 +29,8 @@ func main() {
 
 	// Watch the file and get a callback on change. The callback can do whatever,
 	// like re-load the configuration.
-	// File provider always returns a nil `event`.
-	f.Watch(func(event any, err error) {
+	// The watch stops when the context is cancelled or f.Unwatch() is called.
+	f.Watch(context.Background(), func(event koanf.Event, err error) {
 		if err != nil {
 			log.Printf("watch error: %v", err)
 			return
@@ -31,7 +31,7 @@ func main() {
 	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
 	defer stop()
 
-	if err := p.Watch(func(_ any, err error) {
+	if err := p.Watch(ctx, func(_ koanf.Event, err error) {
 		if err != nil {
 			log.Printf("watch error: %v", err)
 			return
@@ -0,0 +1,69 @@
+package koanf
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/go-viper/mapstructure/v2"
+)
+
+// ErrKeyNotFound is returned by Get and MustGet when the key path
+// doesn't exist in the config.
+var ErrKeyNotFound = errors.New("key not found")
+
+// Get returns the value of the given key path converted to the type T,
+// which can be any scalar, slice, map, or struct type, for instance,
+// koanf.Get[[]int](k, "ports") or koanf.Get[time.Duration](k, "timeout").
+// The conversion is done with mapstructure like Unmarshal: it's weakly typed
+// (eg: "8080" converts to an int), strings convert to time.Duration and to
+// types that implement encoding.TextUnmarshaler, and struct fields are matched
+// with the `koanf` tag. If the path is empty, the whole config is converted.
+//
+// It returns an error that wraps ErrKeyNotFound if the key doesn't exist,
+// or an error naming the key if the value can't be converted to T.
+func Get[T any](ko *Koanf, path string) (T, error) {
+	var out T
+	if path != "" && !ko.Exists(path) {
+		return out, fmt.Errorf("%w: %s", ErrKeyNotFound, path)
+	}
+
+	v := ko.Get(path)
+	if t, ok := v.(T); ok {
+		return t, nil
+	}
+
+	c := defaultDecoderConfig()
+	c.Result = &out
+	c.TagName = "koanf"
+
+	d, err := mapstructure.NewDecoder(c)
+	if err != nil {
+		return out, err
+	}
+	if err := d.Decode(v); err != nil {
+		var zero T
+		return zero, fmt.Errorf("error converting %s (%T) to %T: %w", path, v, zero, err)
+	}
+
+	return out, nil
+}
+
+// GetOr is like Get but returns def if the key path doesn't exist
+// or its value can't be converted to the type T.
+func GetOr[T any](ko *Koanf, path string, def T) T {
+	v, err := Get[T](ko, path)
+	if err != nil {
+		return def
+	}
+	return v
+}
+
+// MustGet is like Get but panics if the key path doesn't exist
+// or its value can't be converted to the type T.
+func MustGet[T any](ko *Koanf, path string) T {
+	v, err := Get[T](ko, path)
+	if err != nil {
+		panic(err)
+	}
+	return v
+}
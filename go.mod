@@ -4,7 +4,7 @@ go 1.23.0
 
 require (
 	github.com/go-viper/mapstructure/v2 v2.4.0
-	github.com/knadh/koanf/maps v0.1.2
+	github.com/knadh/koanf/maps v0.2.0
 	github.com/mitchellh/copystructure v1.2.0
 )
 
@@ -0,0 +1,180 @@
+package koanf
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/knadh/koanf/maps"
+)
+
+// normalizeIndexes converts the bracketed indexes in a key path to
+// delimited ones, for instance, `servers[0].host` to `servers.0.host`.
+// Brackets in quoted parts are left as they are.
+func normalizeIndexes(path, delim string) string {
+	var (
+		b      strings.Builder
+		quoted bool
+	)
+	for i := 0; i < len(path); i++ {
+		c := path[i]
+		switch {
+		case c == '\\' && quoted && i+1 < len(path):
+			b.WriteByte(c)
+			i++
+			c = path[i]
+		case c == '"':
+			quoted = !quoted
+		case c == '[' && !quoted:
+			n := strings.IndexByte(path[i:], ']')
+			if n > 1 && isDigits(path[i+1:i+n]) {
+				b.WriteString(delim)
+				b.WriteString(path[i+1 : i+n])
+				i += n
+				continue
+			}
+		}
+		b.WriteByte(c)
+	}
+	return b.String()
+}
+
+// isDigits checks whether s consists of ASCII digits only.
+func isDigits(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return s != ""
+}
+
+// lookup returns the parts of the key path (see key()) as they are in the
+// config map of the state, or false if the key path doesn't exist. Unlike
+// the key map, the key path may address the elements of slices by their index.
+func (ko *Koanf) lookup(st *state, path string) ([]string, bool) {
+	if p, ok := st.keyMap[path]; ok {
+		return p, true
+	}
+
+	var (
+		parts     = maps.SplitKey(path, ko.conf.Delim)
+		cur   any = st.confMap
+	)
+	for i, k := range parts {
+		switch v := cur.(type) {
+		case map[string]any:
+			k = ko.mapKey(v, k)
+			c, ok := v[k]
+			if !ok {
+				return nil, false
+			}
+			parts[i], cur = k, c
+		case []any:
+			n, ok := maps.Index(v, k)
+			if !ok {
+				return nil, false
+			}
+			parts[i], cur = strconv.Itoa(n), v[n]
+		default:
+			return nil, false
+		}
+	}
+	return parts, true
+}
+
+// mapKey returns the key in the map that matches k, case-insensitively if
+// Conf.CaseInsensitive is set, or k itself.
+func (ko *Koanf) mapKey(mp map[string]any, k string) string {
+	if _, ok := mp[k]; ok || !ko.conf.CaseInsensitive {
+		return k
+	}
+	for name := range mp {
+		if strings.EqualFold(name, k) {
+			return name
+		}
+	}
+	return k
+}
+
+// setIndexed returns the config map to merge into the conf map mp to set the
+// value at the key path if it addresses an element of a slice (or a value
+// nested in one). The slice is updated in mp, which has to be a copy. It
+// returns false if the key path doesn't traverse a slice.
+func (ko *Koanf) setIndexed(mp map[string]any, path string, val any) (map[string]any, bool, error) {
+	var (
+		parts = maps.SplitKey(path, ko.conf.Delim)
+		cur   = mp
+	)
+	for i, k := range parts[:len(parts)-1] {
+		k = ko.mapKey(cur, k)
+		parts[i] = k
+
+		switch v := cur[k].(type) {
+		case map[string]any:
+			cur = v
+		case []any:
+			sl, err := ko.setElem(v, parts[i+1:], val)
+			if err != nil {
+				return nil, false, fmt.Errorf("error setting %s: %w", path, err)
+			}
+
+			// Replace the whole slice.
+			out := map[string]any{}
+			next := out
+			for _, p := range parts[:i] {
+				n := map[string]any{}
+				next[p] = n
+				next = n
+			}
+			next[k] = sl
+			return out, true, nil
+		default:
+			return nil, false, nil
+		}
+	}
+	return nil, false, nil
+}
+
+// setElem sets the value at the key path that begins with an index in the
+// slice. An index equal to the length of the slice appends an element.
+func (ko *Koanf) setElem(sl []any, parts []string, val any) ([]any, error) {
+	i, err := strconv.Atoi(parts[0])
+	if err != nil || i < 0 || i > len(sl) {
+		return nil, fmt.Errorf("invalid index %s for slice of length %d", parts[0], len(sl))
+	}
+	if i == len(sl) {
+		sl = append(sl, nil)
+	}
+
+	v, err := ko.setValue(sl[i], parts[1:], val)
+	if err != nil {
+		return nil, err
+	}
+	sl[i] = v
+	return sl, nil
+}
+
+// setValue sets the value at the key path in cur and returns cur. Maps are
+// created for the key path parts that don't exist.
+func (ko *Koanf) setValue(cur any, parts []string, val any) (any, error) {
+	if len(parts) == 0 {
+		return val, nil
+	}
+
+	switch v := cur.(type) {
+	case []any:
+		return ko.setElem(v, parts, val)
+	case map[string]any:
+		k := ko.mapKey(v, parts[0])
+		c, err := ko.setValue(v[k], parts[1:], val)
+		if err != nil {
+			return nil, err
+		}
+		v[k] = c
+		return v, nil
+	}
+
+	// Replace other values with a map.
+	return ko.setValue(map[string]any{}, parts, val)
+}
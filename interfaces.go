@@ -1,5 +1,7 @@
 package koanf
 
+import "context"
+
 // Provider represents a configuration provider. Providers can
 // read configuration from a source (file, HTTP etc.)
 type Provider interface {
@@ -13,8 +15,114 @@ type Provider interface {
 	Read() (map[string]any, error)
 }
 
+// Writer is implemented by Providers that can write the configuration
+// back to their source (file, KV store etc.) See Koanf.Save().
+type Writer interface {
+	// WriteBytes writes the entire configuration as raw []bytes
+	// serialized with a Parser.
+	WriteBytes([]byte) error
+
+	// Write writes the nested config map, which is like the
+	// one returned by Provider.Read().
+	Write(map[string]any) error
+}
+
+// SensitiveProvider is implemented by Providers of secrets, for instance,
+// vault. The values of all the keys loaded from a Provider whose Sensitive()
+// returns true are marked as sensitive and redacted in the output of
+// Sprint(), All() etc. See Conf.Sensitive.
+type SensitiveProvider interface {
+	Sensitive() bool
+}
+
 // Parser represents a configuration format parser.
 type Parser interface {
 	Unmarshal([]byte) (map[string]any, error)
 	Marshal(map[string]any) ([]byte, error)
 }
+
+// Editor is implemented by Parsers that can apply a config map to an existing
+// document in their format, preserving its comments, key order and formatting,
+// unlike Marshal, which regenerates the document. See Koanf.Edit().
+type Editor interface {
+	// Edit returns the document b updated to represent the nested config map.
+	// Keys that are not in the map are removed from the document and keys
+	// that are not in the document are added to it.
+	Edit(b []byte, mp map[string]any) ([]byte, error)
+}
+
+// Annotator is implemented by Parsers that can write comments in their
+// format. It's used to generate commented example configs. See Sample().
+type Annotator interface {
+	// MarshalAnnotated marshals the nested config map like Marshal, writing
+	// the keys in the order of the full key paths (joined with delim) in
+	// keys, followed by the keys that are not in it in sorted order. The
+	// comments, keyed by full key paths, are written above their keys.
+	MarshalAnnotated(mp map[string]any, keys []string, comments map[string]string, delim string) ([]byte, error)
+}
+
+// Watcher represents a Provider that can watch its source for changes
+// and notify a callback, which would typically re-Load() the config.
+type Watcher interface {
+	// Watch starts watching the source in a goroutine and returns immediately.
+	// cb is invoked on every change, or with a non-nil error when watching fails.
+	// The watch is stopped when ctx is cancelled or Unwatch() is called.
+	Watch(ctx context.Context, cb func(ev Event, err error)) error
+
+	// Unwatch stops an active watch and releases its resources.
+	// It is a no-op if there is no active watch.
+	Unwatch() error
+}
+
+// Event represents a change notification sent by a Watcher.
+type Event struct {
+	// Key is the file path or the remote key that changed, if the
+	// provider knows it.
+	Key string
+
+	// Raw is the underlying provider specific event, for instance,
+	// fsnotify.Event or *clientv3.Event. It may be nil.
+	Raw any
+}
+
+// Validator validates a config map, for instance, against a schema.
+// When set on a Koanf instance (Conf.Validator) or a Load() (WithValidator),
+// the result of every merge is validated before it replaces the instance's
+// config, and an invalid result is rejected with the validation error.
+type Validator interface {
+	// Validate validates the nested config map. It must not modify the map.
+	Validate(map[string]any) error
+}
+
+// ValidatorFunc is an adapter to use an ordinary function as a Validator.
+type ValidatorFunc func(map[string]any) error
+
+// Validate calls f(mp).
+func (f ValidatorFunc) Validate(mp map[string]any) error {
+	return f(mp)
+}
+
+// Resolver resolves secret references in config values, for instance,
+// `vault://secret/data/db#password` or `file:///run/secrets/db`, to the
+// secret values. Resolvers are registered by URL scheme in Conf.Resolvers.
+type Resolver interface {
+	// Resolve takes the complete reference, including the scheme, and
+	// returns the secret value.
+	Resolve(ref string) (any, error)
+}
+
+// ResolverFunc is an adapter to use an ordinary function as a Resolver.
+type ResolverFunc func(ref string) (any, error)
+
+// Resolve calls f(ref).
+func (f ResolverFunc) Resolve(ref string) (any, error) {
+	return f(ref)
+}
+
+// Evaluator evaluates query expressions in a query language, for instance,
+// JMESPath, against a config map. See Conf.Evaluator and Eval().
+type Evaluator interface {
+	// Eval evaluates the expression against the nested config map and
+	// returns the result. It must not modify the map.
+	Eval(expr string, mp map[string]any) (any, error)
+}
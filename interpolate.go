@@ -0,0 +1,225 @@
+package koanf
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/knadh/koanf/maps"
+)
+
+// interpolator resolves ${...} references in the values of a config map.
+type interpolator struct {
+	src   map[string]any
+	delim string
+
+	// Resolved values by key path and the stack of keys being resolved
+	// for detecting cycles.
+	done  map[string]any
+	stack []string
+
+	// Key paths referenced by the values of every key path.
+	refs map[string][]string
+}
+
+// interpolate returns a copy of the config map mp with the references
+// in its string values resolved, and the key paths referenced by the values
+// of every key path. It returns an error on reference cycles.
+//
+// `${key.path}` is replaced with the value of the key and `${env:NAME}` with
+// the value of the environment variable. `${key.path:-default}` and
+// `${env:NAME:-default}` use the default if the key is missing or the variable
+// is unset or empty. A value that is a single reference takes the type of the
+// referenced value (eg: an int or a map). References that can't be resolved
+// are left as they are. `$${` is an escaped, literal `${`.
+func interpolate(mp map[string]any, delim string) (map[string]any, map[string][]string, error) {
+	in := &interpolator{
+		src:   mp,
+		delim: delim,
+		done:  make(map[string]any),
+		refs:  make(map[string][]string),
+	}
+
+	out := make(map[string]any, len(mp))
+	for k := range mp {
+		v, err := in.resolveKey(maps.QuoteKey(k, delim))
+		if err != nil {
+			return nil, nil, err
+		}
+		out[k] = v
+	}
+	return out, in.refs, nil
+}
+
+// resolveKey returns the resolved value of the key path.
+func (in *interpolator) resolveKey(key string) (any, error) {
+	if v, ok := in.done[key]; ok {
+		return v, nil
+	}
+
+	for i, k := range in.stack {
+		if k == key {
+			return nil, fmt.Errorf("interpolation cycle: %s -> %s", strings.Join(in.stack[i:], " -> "), key)
+		}
+	}
+
+	in.stack = append(in.stack, key)
+	v, err := in.resolveValue(key, maps.Search(in.src, maps.SplitKey(key, in.delim)))
+	in.stack = in.stack[:len(in.stack)-1]
+	if err != nil {
+		return nil, err
+	}
+
+	in.done[key] = v
+	return v, nil
+}
+
+// resolveValue resolves the references in the value of the given key path.
+func (in *interpolator) resolveValue(key string, v any) (any, error) {
+	switch v := v.(type) {
+	case string:
+		return in.resolveString(v)
+
+	case map[string]any:
+		out := make(map[string]any, len(v))
+		for k := range v {
+			r, err := in.resolveKey(joinKey(key, maps.QuoteKey(k, in.delim), in.delim))
+			if err != nil {
+				return nil, err
+			}
+			out[k] = r
+		}
+		return out, nil
+
+	case []any:
+		out := make([]any, len(v))
+		for i, el := range v {
+			r, err := in.resolveValue(joinKey(key, strconv.Itoa(i), in.delim), el)
+			if err != nil {
+				return nil, err
+			}
+			out[i] = r
+		}
+		return out, nil
+	}
+
+	return v, nil
+}
+
+// resolveString resolves the references in a string. If the string is a
+// single reference, the referenced value is returned as it is.
+func (in *interpolator) resolveString(s string) (any, error) {
+	if !strings.Contains(s, "${") {
+		return s, nil
+	}
+
+	// A single reference retains the type of the referenced value.
+	if strings.HasPrefix(s, "${") && strings.Index(s, "}") == len(s)-1 {
+		v, ok, err := in.lookup(s[2 : len(s)-1])
+		if err != nil || !ok {
+			return s, err
+		}
+
+		// Don't share the referenced map between the keys.
+		if mp, ok := v.(map[string]any); ok {
+			return maps.Copy(mp), nil
+		}
+		return v, nil
+	}
+
+	var b strings.Builder
+	for {
+		i := strings.Index(s, "${")
+		if i < 0 {
+			b.WriteString(s)
+			break
+		}
+
+		// Escaped $${.
+		if i > 0 && s[i-1] == '$' {
+			b.WriteString(s[:i-1])
+			b.WriteString("${")
+			s = s[i+2:]
+			continue
+		}
+
+		end := strings.Index(s[i:], "}")
+		if end < 0 {
+			b.WriteString(s)
+			break
+		}
+		end += i
+
+		b.WriteString(s[:i])
+		v, ok, err := in.lookup(s[i+2 : end])
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			b.WriteString(fmt.Sprint(v))
+		} else {
+			b.WriteString(s[i : end+1])
+		}
+		s = s[end+1:]
+	}
+
+	return b.String(), nil
+}
+
+// lookup returns the value of a reference expression: `key.path` or
+// `env:NAME`, optionally followed by `:-default`.
+func (in *interpolator) lookup(expr string) (any, bool, error) {
+	name, def, hasDef := strings.Cut(expr, ":-")
+
+	if env, ok := strings.CutPrefix(name, "env:"); ok {
+		if v := os.Getenv(env); v != "" {
+			return v, true, nil
+		}
+		return def, hasDef, nil
+	}
+
+	parts := maps.SplitKey(name, in.delim)
+	if maps.Search(in.src, parts) == nil {
+		return def, hasDef, nil
+	}
+
+	// Normalize the quoting of key parts so that the key matches the resolved keys.
+	name = maps.JoinKey(parts, in.delim)
+
+	// Record the reference for the key being resolved.
+	if n := len(in.stack); n > 0 {
+		in.refs[in.stack[n-1]] = append(in.refs[in.stack[n-1]], name)
+	}
+
+	v, err := in.resolveKey(name)
+	if err != nil {
+		return nil, false, err
+	}
+	return v, true, nil
+}
+
+// escapeRefs escapes the ${ references in the string values of an
+// already resolved config map in place so that interpolating it again
+// produces the same values.
+func escapeRefs(mp map[string]any) map[string]any {
+	for k, v := range mp {
+		mp[k] = escapeValue(v)
+	}
+	return mp
+}
+
+// escapeValue escapes the ${ references in a value in place.
+func escapeValue(v any) any {
+	switch v := v.(type) {
+	case string:
+		return strings.ReplaceAll(v, "${", "$${")
+	case map[string]any:
+		return escapeRefs(v)
+	case []any:
+		for i, el := range v {
+			v[i] = escapeValue(el)
+		}
+	}
+	return v
+}
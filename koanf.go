@@ -3,11 +3,15 @@ package koanf
 import (
 	"bytes"
 	"encoding"
+	"errors"
 	"fmt"
+	"io/fs"
 	"reflect"
 	"sort"
 	"strconv"
+	"strings"
 	"sync"
+	"sync/atomic"
 
 	"github.com/go-viper/mapstructure/v2"
 	"github.com/knadh/koanf/maps"
@@ -15,14 +19,105 @@ import (
 )
 
 // Koanf is the configuration apparatus.
+//
+// The config is held in an immutable state that is replaced atomically on
+// every change, so reads never lock and never block on, or observe a partial,
+// Load() or other change. Changes are serialized and copy the config map.
 type Koanf struct {
+	st   atomic.Pointer[state]
+	conf Conf
+
+	// Serializes changes to the config.
+	mu sync.Mutex
+
+	// Instances returned by Snapshot() can't be changed.
+	readOnly bool
+
+	// Change subscriptions registered with OnChange().
+	subs   []*subscription
+	subsMu sync.RWMutex
+
+	// Change notifications waiting to be delivered in the order of the
+	// changes, and whether a goroutine is delivering them.
+	pending    []*change
+	delivering bool
+	notifyMu   sync.Mutex
+}
+
+// state is a point-in-time state of the config. Once published, it's never
+// modified. Changes build a new state from a copy of the current one.
+type state struct {
 	confMap     map[string]any
 	confMapFlat map[string]any
 	keyMap      KeyMap
-	conf        Conf
-	mu          sync.RWMutex
+
+	// Provenance of every flattened key and the number of merges
+	// so far. Only maintained if Conf.Provenance is set.
+	origins map[string][]Origin
+	loads   int
+
+	// Config map with unresolved ${...} references that confMap is
+	// interpolated from. Only maintained if Conf.Interpolate is set.
+	tplMap map[string]any
+
+	// Lowercased flattened keys (including parents) mapped to the keys in
+	// keyMap. Only maintained if Conf.CaseInsensitive is set.
+	foldedKeys map[string]string
+
+	// The keys in keyMap whose top-level part contains the delimiter by
+	// their parts joined without quotes (lowercased if Conf.CaseInsensitive
+	// is set), eg: `parent.name` -> `"parent.name"`.
+	unquotedKeys map[string]string
+
+	// Keys whose values were resolved from secret references, or loaded
+	// as sensitive.
+	sensitive map[string]struct{}
+
+	// Key paths referenced by the values of every key path in tplMap, and the
+	// keys whose values reference sensitive keys. Only maintained if
+	// Conf.Interpolate is set.
+	refs          map[string][]string
+	sensitiveRefs map[string]struct{}
+
+	// Changes made with Set() and Delete() in order, which Edit()
+	// applies to documents.
+	edits []edit
+}
+
+// edit is a change made with Set() or Delete().
+type edit struct {
+	key string
+	val any
+	del bool
 }
 
+// withEdit returns the changes in the state followed by e. The
+// slice is copied as it may be shared with other states.
+func (st *state) withEdit(e edit) []edit {
+	return append(st.edits[:len(st.edits):len(st.edits)], e)
+}
+
+// clone returns a copy of the state to build a new state from. The config
+// maps are shared and have to be replaced, not modified. The origins and
+// sensitive keys are copied so that they can be modified.
+func (st *state) clone() *state {
+	out := *st
+
+	out.origins = make(map[string][]Origin, len(st.origins))
+	for k, v := range st.origins {
+		out.origins[k] = v
+	}
+	out.sensitive = make(map[string]struct{}, len(st.sensitive))
+	for k := range st.sensitive {
+		out.sensitive[k] = struct{}{}
+	}
+	return &out
+}
+
+// ErrReadOnly is returned when changing the config of a read-only
+// instance returned by Snapshot().
+var ErrReadOnly = errors.New("config snapshot is read-only")
+
 // Conf is the Koanf configuration.
 type Conf struct {
 	// Delim is the delimiter to use
@@ -35,6 +130,91 @@ type Conf struct {
 	// the first loaded file will define the desired type, and if the second file loads
 	// a different type will cause an error.
 	StrictMerge bool
+
+	// Provenance enables recording of the source (provider, parser, load order)
+	// of every value merged into the config map so that Explain() can report
+	// where a key's value came from. It adds bookkeeping to every merge.
+	Provenance bool
+
+	// Validator, if set, validates the config resulting from every merge
+	// (Load, Set, Merge etc.) before it replaces the existing config. A merge
+	// that produces an invalid config is rejected with the validation error
+	// and the existing config is retained. As every merge is validated, the
+	// config should be valid after the first Load, for instance, by loading
+	// defaults first. Alternatively, use the WithValidator option on the final Load
+	// or Layers, which only validate the fully merged config.
+	Validator Validator
+
+	// Interpolate enables the resolution of references in string values after
+	// every merge, for instance, `http://${server.host}:${server.port}/api`.
+	//   - `${key.path}` is replaced with the value of another key.
+	//   - `${env:NAME}` is replaced with the value of an environment variable.
+	//   - `${key.path:-default}` and `${env:NAME:-default}` use the default
+	//     if the key doesn't exist or the variable is unset or empty.
+	//   - `$${` is an escaped, literal `${`.
+	// A value that is a single reference takes the type of the referenced
+	// value, for instance, an int or a map. References that can't be resolved
+	// are left as they are so that later loads can provide the keys. A merge
+	// that results in a reference cycle is rejected with an error.
+	//
+	// The unresolved values are retained and all references are resolved
+	// again on every merge, so a reference always has the value of the
+	// key from the last load that set it.
+	Interpolate bool
+
+	// Resolvers is a registry of secret reference resolvers by URL scheme,
+	// for instance, "vault" for `vault://secret/data/db#password`. String values
+	// in a map being merged (by Load, Set etc.) that start with a registered
+	// scheme and :// are replaced with the value returned by the Resolver
+	// before merging. If a reference can't be resolved, the merge is
+	// rejected with an error that names the referencing key.
+	//
+	// The keys with resolved values are marked as sensitive. See IsSensitive().
+	Resolvers map[string]Resolver
+
+	// Sensitive is a list of glob patterns of key paths whose values are
+	// sensitive, for instance, `db.password`, `*.secret`, `**.*_token`. In a
+	// pattern, `*` matches a single (or a part of a single) key path segment
+	// and `**` matches any number of segments. Keys under a matching
+	// path are sensitive as well.
+	//
+	// The values of sensitive keys are redacted in the output of Sprint(),
+	// Print(), All(), and MarshalRedacted(). Get() and the other getters,
+	// Raw(), Marshal(), and Unmarshal() return the real values. Keys are also
+	// marked as sensitive when they are resolved from secret references
+	// (see Resolvers), loaded with the WithSensitive option, or loaded from
+	// a SensitiveProvider.
+	Sensitive []string
+
+	// CaseInsensitive makes key paths case-insensitive. Get(), Exists(),
+	// Delete(), Cut() etc. find keys irrespective of their case, and keys that
+	// differ only in case are merged by Load(), Set(), Merge() etc. into a
+	// single key, for instance, `DB.HOST` from env.Provider into `db.host` from
+	// a file. The config map retains the casing of the first load that set a
+	// key, which is what Keys(), Raw(), and Marshal() return.
+	CaseInsensitive bool
+
+	// Evaluator evaluates the query expressions given to Eval(), for
+	// instance, JMESPath expressions with evaluators/jmespath.
+	Evaluator Evaluator
+
+	// Aliases maps deprecated key paths to the key paths that replace them,
+	// for instance, `db.addr` => `database.host`. Values in a map being merged
+	// (by Load, Set etc.) under a deprecated key are moved to the new key
+	// before merging, so the config only has the new keys. A deprecated key
+	// that's a map is moved with everything under it.
+	Aliases map[string]string
+
+	// AliasConflict is the policy for a map being merged that has both a
+	// deprecated key and the key that replaces it. By default, the value
+	// of the new key is retained and the conflict is reported to OnDeprecated.
+	// Keys merged by different loads don't conflict, the later load
+	// overrides the key like any other.
+	AliasConflict AliasConflict
+
+	// OnDeprecated, if set, is called after a merge for every deprecated key
+	// in the merged map, for instance, to log a warning.
+	OnDeprecated func(d Deprecation)
 }
 
 // KeyMap represents a map of flattened delimited keys and the non-delimited
@@ -64,6 +244,68 @@ type UnmarshalConf struct {
 	// ```
 	FlatPaths     bool
 	DecoderConfig *mapstructure.DecoderConfig
+
+	// If this is set to true, the unmarshalled struct is validated against
+	// the comma separated rules in the fields' validation tags, for example:
+	// ```
+	// type Server struct {
+	// 	Addr    string        `koanf:"addr" validate:"required,hostport"`
+	// 	Mode    string        `koanf:"mode" validate:"oneof=debug release"`
+	// 	Workers int           `koanf:"workers" validate:"min=1,max=64"`
+	// 	Timeout time.Duration `koanf:"timeout" validate:"min=1s"`
+	// 	Webhook string        `koanf:"webhook" validate:"url"`
+	// 	Name    string        `koanf:"name" validate:"min=3,regex=^[a-z-]+$"`
+	// }
+	// ```
+	// `required` requires the key to be present in the config or the field
+	// to have a non-zero value. min and max compare numbers by value,
+	// durations against duration strings, and strings, slices, and maps by
+	// their length. `regex` must be the last rule in the tag. Other rules are
+	// skipped for fields that are absent from the config and zero.
+	//
+	// All violations are returned together in a *ValidationError.
+	Validate bool
+
+	// ValidateTag is the struct field tag with the validation rules.
+	// `validate` is used if left empty.
+	ValidateTag string
+
+	// If this is set to true, the values in the fields' default tags are
+	// used for the fields whose keys are absent from the config, for example:
+	// ```
+	// type Server struct {
+	// 	Addr    string         `koanf:"addr" default:"localhost:8080"`
+	// 	Timeout time.Duration  `koanf:"timeout" default:"5s"`
+	// 	Origins []string       `koanf:"origins" default:"a.com,b.com"`
+	// 	Limits  map[string]int `koanf:"limits" default:"read=10,write=5"`
+	// }
+	// ```
+	// Slices are comma separated lists and maps are comma separated key=value
+	// pairs. Fields of nested structs and of structs in slices and maps that
+	// are in the config get their defaults too. Pointers to structs that are
+	// absent from the config are left nil. The defaults are not added to
+	// the loaded config. To do that, load them with the Defaults provider.
+	Defaults bool
+
+	// DefaultTag is the struct field tag with the default value.
+	// `default` is used if left empty.
+	DefaultTag string
+
+	// If this is set to true, a *StrictError is returned listing the keys in
+	// the config (at the unmarshalled path) that don't match any field of the
+	// struct, like typos, with their full key paths, for instance, `db.hsot`.
+	// Unlike mapstructure's ErrorUnused, keys of nested structs, and of
+	// structs in slices and maps, are checked. Keys under a field with the
+	// `remain` tag option or of type any are not reported. With Validate, the
+	// struct is validated regardless, and both errors are returned joined,
+	// to be checked with errors.As.
+	Strict bool
+
+	// If this is set to true, a *StrictError is returned listing the fields
+	// of the struct that got no value, with their full key paths, in Unset.
+	// Fields that have a default with Defaults are not unset. It can be used
+	// with or without Strict.
+	StrictUnset bool
 }
 
 // New returns a new instance of Koanf. delim is the delimiter to use
@@ -78,59 +320,78 @@ func New(delim string) *Koanf {
 
 // NewWithConf returns a new instance of Koanf based on the Conf.
 func NewWithConf(conf Conf) *Koanf {
-	return &Koanf{
+	ko := &Koanf{conf: conf}
+	ko.st.Store(newState())
+	return ko
+}
+
+// newState returns an empty state.
+func newState() *state {
+	return &state{
 		confMap:     make(map[string]any),
 		confMapFlat: make(map[string]any),
 		keyMap:      make(KeyMap),
-		conf:        conf,
+		foldedKeys:  make(map[string]string),
+		origins:     make(map[string][]Origin),
+		tplMap:      make(map[string]any),
+		sensitive:   make(map[string]struct{}),
 	}
 }
 
+// Snapshot returns a read-only, point-in-time view of the config as an
+// instance whose config doesn't change with subsequent changes to ko.
+// It's cheap as the config is immutable and is shared, not copied.
+// Load(), Set(), Merge(), and MergeAt() on the snapshot return ErrReadOnly
+// and Delete() does nothing. Copy() returns a snapshot's config as a new,
+// writable instance.
+func (ko *Koanf) Snapshot() *Koanf {
+	n := &Koanf{conf: ko.conf, readOnly: true}
+	n.st.Store(ko.st.Load())
+	return n
+}
+
 // Load takes a Provider that either provides a parsed config map[string]any
 // in which case pa (Parser) can be nil, or raw bytes to be parsed, where a Parser
 // can be provided to parse. Additionally, options can be passed which modify the
 // load behavior, such as passing a custom merge function.
 func (ko *Koanf) Load(p Provider, pa Parser, opts ...Option) error {
-	var (
-		mp  map[string]any
-		err error
-	)
+	mp, err := read(p, pa)
+	if err != nil {
+		return err
+	}
 
+	return ko.merge(mp, newLoadOptions(p, pa, opts))
+}
+
+// read reads the config map from a Provider, either directly, or
+// as raw bytes parsed with the Parser if one is given.
+func read(p Provider, pa Parser) (map[string]any, error) {
 	if p == nil {
-		return fmt.Errorf("load received a nil provider")
+		return nil, fmt.Errorf("load received a nil provider")
 	}
 
 	// No Parser is given. Call the Provider's Read() method to get
 	// the config map.
 	if pa == nil {
-		mp, err = p.Read()
-		if err != nil {
-			return err
-		}
-	} else {
-		// There's a Parser. Get raw bytes from the Provider to parse.
-		b, err := p.ReadBytes()
-		if err != nil {
-			return err
-		}
-		mp, err = pa.Unmarshal(b)
-		if err != nil {
-			return err
-		}
+		return p.Read()
 	}
 
-	return ko.merge(mp, newOptions(opts))
+	// There's a Parser. Get raw bytes from the Provider to parse.
+	b, err := p.ReadBytes()
+	if err != nil {
+		return nil, err
+	}
+	return pa.Unmarshal(b)
 }
 
 // Keys returns the slice of all flattened keys in the loaded configuration
 // sorted alphabetically.
 func (ko *Koanf) Keys() []string {
-	ko.mu.RLock()
-	out := make([]string, 0, len(ko.confMapFlat))
-	for k := range ko.confMapFlat {
+	st := ko.st.Load()
+	out := make([]string, 0, len(st.confMapFlat))
+	for k := range st.confMapFlat {
 		out = append(out, k)
 	}
-	ko.mu.RUnlock()
 	sort.Strings(out)
 	return out
 }
@@ -138,50 +399,59 @@ func (ko *Koanf) Keys() []string {
 // KeyMap returns a map of flattened keys and the individual parts of the
 // key as slices. eg: "parent.child.key" => ["parent", "child", "key"].
 func (ko *Koanf) KeyMap() KeyMap {
-	ko.mu.RLock()
-	out := make(KeyMap, len(ko.keyMap))
-	for key, parts := range ko.keyMap {
+	st := ko.st.Load()
+	out := make(KeyMap, len(st.keyMap))
+	for key, parts := range st.keyMap {
 		out[key] = make([]string, len(parts))
 		copy(out[key], parts)
 	}
-	ko.mu.RUnlock()
 	return out
 }
 
 // All returns a map of all flattened key paths and their values.
+// The values of sensitive keys, including the elements of slices,
+// are replaced with RedactedValue.
 // Note that it uses maps.Copy to create a copy that uses
 // json.Marshal which changes the numeric types to float64.
 func (ko *Koanf) All() map[string]any {
-	ko.mu.RLock()
-	defer ko.mu.RUnlock()
-	return maps.Copy(ko.confMapFlat)
+	st := ko.st.Load()
+	out := maps.Copy(st.confMapFlat)
+	for k, v := range out {
+		out[k] = ko.redact(st, k, v)
+	}
+	return out
 }
 
 // Raw returns a copy of the full raw conf map.
 // Note that it uses maps.Copy to create a copy that uses
 // json.Marshal which changes the numeric types to float64.
 func (ko *Koanf) Raw() map[string]any {
-	ko.mu.RLock()
-	defer ko.mu.RUnlock()
-	return maps.Copy(ko.confMap)
+	return maps.Copy(ko.st.Load().confMap)
 }
 
 // Sprint returns a key -> value string representation
 // of the config map with keys sorted alphabetically.
+// The values of sensitive keys, including the elements of slices,
+// are replaced with RedactedValue.
 func (ko *Koanf) Sprint() string {
+	st := ko.st.Load()
+	keys := make([]string, 0, len(st.confMapFlat))
+	for k := range st.confMapFlat {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
 	b := bytes.Buffer{}
-	keys := ko.Keys()
-	ko.mu.RLock()
 	for _, k := range keys {
-		v := ko.confMapFlat[k]
-		b.WriteString(fmt.Sprintf("%s -> %v\n", k, v))
+		b.WriteString(fmt.Sprintf("%s -> %v\n", k, ko.redact(st, k, st.confMapFlat[k])))
 	}
-	ko.mu.RUnlock()
 	return b.String()
 }
 
 // Print prints a key -> value string representation
 // of the config map with keys sorted alphabetically.
+// The values of sensitive keys, including the elements of slices,
+// are replaced with RedactedValue.
 func (ko *Koanf) Print() {
 	fmt.Print(ko.Sprint())
 }
@@ -195,13 +465,49 @@ func (ko *Koanf) Print() {
 func (ko *Koanf) Cut(path string) *Koanf {
 	out := make(map[string]any)
 
+	// Cut from a single state so that the config doesn't change midway.
+	st := ko.st.Load()
+	path = ko.key(st, path)
+
 	// Cut only makes sense if the requested key path is a map.
-	if v, ok := ko.Get(path).(map[string]any); ok {
+	if v, ok := ko.get(st, path).(map[string]any); ok {
 		out = v
 	}
 
-	n := New(ko.conf.Delim)
-	_ = n.merge(out, new(options))
+	n := ko.derive(st, out, "Cut", ko.sensitiveUnder(st, path))
+
+	// Carry over the provenance under the path.
+	// n isn't shared yet, so its state can be modified in place.
+	if ko.conf.Provenance {
+		n.st.Load().origins = cutOrigins(st.origins, path, ko.conf.Delim)
+	}
+	return n
+}
+
+// derive returns a new instance with the config map mp, which is taken from
+// the state st, loaded, and the keys for which sensitive returns true marked
+// as sensitive. It has the same Conf, except for the validator that applies to
+// the whole config and not to a part of it.
+func (ko *Koanf) derive(st *state, mp map[string]any, provider string, sensitive func(key string, v any) bool) *Koanf {
+	conf := ko.conf
+	conf.Validator = nil
+
+	// The values are already resolved. Escape them so that they
+	// are not interpolated again.
+	if conf.Interpolate {
+		mp = escapeRefs(mp)
+	}
+
+	n := NewWithConf(conf)
+	_ = n.mergeResolved(resolved{mp: mp}, &options{provider: provider})
+
+	// n isn't shared yet, so its state can be modified in place.
+	ns := n.st.Load()
+	for k, v := range ns.confMapFlat {
+		if sensitive(k, v) {
+			ns.sensitive[k] = struct{}{}
+		}
+	}
 	return n
 }
 
@@ -213,7 +519,7 @@ func (ko *Koanf) Copy() *Koanf {
 // Merge merges the config map of a given Koanf instance into
 // the current instance.
 func (ko *Koanf) Merge(in *Koanf) error {
-	return ko.merge(in.Raw(), new(options))
+	return ko.merge(in.Raw(), &options{provider: "Merge"})
 }
 
 // MergeAt merges the config map of a given Koanf instance into
@@ -231,17 +537,40 @@ func (ko *Koanf) MergeAt(in *Koanf, path string) error {
 		path: in.Raw(),
 	}, ko.conf.Delim)
 
-	return ko.merge(n, new(options))
+	return ko.merge(n, &options{provider: "MergeAt"})
+}
+
+// Diff compares the config of the Koanf instance (old) with that of the given
+// instance (new) and returns the flattened keys that were added, removed, or whose
+// values changed in the given instance. For instance, the config before and after
+// a reload can be compared to decide which subsystems need to be restarted.
+// The values in the returned changes are copies.
+func (ko *Koanf) Diff(other *Koanf) maps.Changes {
+	return maps.Diff(ko.Raw(), other.Raw(), ko.conf.Delim)
 }
 
 // Set sets the value at a specific key.
 func (ko *Koanf) Set(key string, val any) error {
+	// Existing flat keys that contain the delimiter are set and not
+	// shadowed by a nested key, eg: `parent.name` for `{"parent.name": 1}`.
+	key = ko.key(ko.st.Load(), key)
+
 	// Unflatten the config map with the given key path.
 	n := maps.Unflatten(map[string]any{
 		key: val,
 	}, ko.conf.Delim)
 
-	return ko.merge(n, new(options))
+	// If the key path addresses an element of a slice, the slice
+	// is replaced with a copy that has the element set.
+	prepare := func(dest map[string]any) (map[string]any, error) {
+		mp, ok, err := ko.setIndexed(dest, key, maps.Search(n, maps.SplitKey(key, ko.conf.Delim)))
+		if err != nil || !ok {
+			return nil, err
+		}
+		return mp, nil
+	}
+
+	return ko.merge(n, &options{provider: "Set", prepare: prepare, edit: &edit{key: key, val: val}})
 }
 
 // Marshal takes a Parser implementation and marshals the config map into bytes,
@@ -250,6 +579,134 @@ func (ko *Koanf) Marshal(p Parser) ([]byte, error) {
 	return p.Marshal(ko.Raw())
 }
 
+// Edit applies the changes made with Set() and Delete() to the document b,
+// which is typically the source the config was loaded from, and returns the
+// updated document. Only the keys in the document and the changed keys are
+// written, and not the keys loaded from other sources. The values are written
+// as they are in the document and as they were set, with secret and ${...}
+// references unresolved. If the Parser implements Editor, the document's
+// comments, key order and formatting are preserved. Otherwise, the result is
+// marshalled like Marshal() does.
+func (ko *Koanf) Edit(p Parser, b []byte) ([]byte, error) {
+	var doc map[string]any
+	if len(b) > 0 {
+		mp, err := p.Unmarshal(b)
+		if err != nil {
+			return nil, err
+		}
+		doc = mp
+	}
+
+	mp, err := ko.applyEdits(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	if e, ok := p.(Editor); ok && len(b) > 0 {
+		return e.Edit(b, mp)
+	}
+	return p.Marshal(mp)
+}
+
+// Save writes the changes made with Set() and Delete() to a Writer, for
+// instance, a file or a KV store. If the Writer is also a Provider, the
+// changes are applied to the config read from it like Edit() does, so that
+// the keys loaded from other sources aren't written to it. If a Parser is
+// given, the config is serialized with it and written with
+// Writer.WriteBytes(). Otherwise, the config map is written with Writer.Write().
+// A source that doesn't exist yet, which Providers report with an error that
+// wraps fs.ErrNotExist, is saved with only the changes. Other read errors
+// are returned and nothing is written.
+func (ko *Koanf) Save(w Writer, p Parser) error {
+	if w == nil {
+		return errors.New("save received a nil writer")
+	}
+
+	r, _ := w.(Provider)
+	if p == nil {
+		var doc map[string]any
+		if r != nil {
+			d, err := r.Read()
+			if err != nil && !errors.Is(err, fs.ErrNotExist) {
+				return fmt.Errorf("error reading the config to save: %w", err)
+			}
+			doc = d
+		}
+
+		mp, err := ko.applyEdits(doc)
+		if err != nil {
+			return err
+		}
+		return w.Write(mp)
+	}
+
+	var src []byte
+	if r != nil {
+		b, err := r.ReadBytes()
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return fmt.Errorf("error reading the config to save: %w", err)
+		}
+		src = b
+	}
+
+	b, err := ko.Edit(p, src)
+	if err != nil {
+		return err
+	}
+	return w.WriteBytes(b)
+}
+
+// applyEdits applies the changes made with Set() and Delete() to the
+// config map doc, which is modified, and returns it.
+func (ko *Koanf) applyEdits(doc map[string]any) (map[string]any, error) {
+	var (
+		delim = ko.conf.Delim
+		fold  = ko.conf.CaseInsensitive
+	)
+	if doc == nil {
+		doc = make(map[string]any)
+	}
+	maps.IntfaceKeysToStrings(doc)
+
+	for _, e := range ko.st.Load().edits {
+		if e.del {
+			if e.key == "" {
+				doc = make(map[string]any)
+			} else if path, ok := findPath(doc, maps.SplitKey(e.key, delim), fold); ok {
+				maps.Delete(doc, path)
+			}
+			continue
+		}
+
+		// Set the value like Set() does.
+		n, ok, err := ko.setIndexed(doc, e.key, e.val)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			n = maps.Copy(maps.Unflatten(map[string]any{e.key: e.val}, delim))
+			if len(ko.conf.Aliases) > 0 {
+				if n, _, err = ko.moveAliases(n); err != nil {
+					return nil, err
+				}
+			}
+		}
+		if fold {
+			n = foldCase(n, doc)
+		}
+		maps.Merge(n, doc)
+	}
+
+	return doc, nil
+}
+
+// MarshalRedacted is like Marshal but the values of sensitive keys
+// are replaced with RedactedValue. It's useful for logging or
+// displaying the config. See Conf.Sensitive.
+func (ko *Koanf) MarshalRedacted(p Parser) ([]byte, error) {
+	return p.Marshal(ko.redacted())
+}
+
 // Unmarshal unmarshals a given key path into the given struct using
 // the mapstructure lib. If no path is specified, the whole map is unmarshalled.
 // `koanf` is the struct field tag used to match field names. To customize,
@@ -263,13 +720,7 @@ func (ko *Koanf) Unmarshal(path string, o any) error {
 // of the unmarshal behaviour.
 func (ko *Koanf) UnmarshalWithConf(path string, o any, c UnmarshalConf) error {
 	if c.DecoderConfig == nil {
-		c.DecoderConfig = &mapstructure.DecoderConfig{
-			DecodeHook: mapstructure.ComposeDecodeHookFunc(
-				mapstructure.StringToTimeDurationHookFunc(),
-				textUnmarshalerHookFunc()),
-			Metadata:         nil,
-			WeaklyTypedInput: true,
-		}
+		c.DecoderConfig = defaultDecoderConfig()
 	}
 
 	c.DecoderConfig.Result = o
@@ -294,34 +745,103 @@ func (ko *Koanf) UnmarshalWithConf(path string, o any, c UnmarshalConf) error {
 		}
 	}
 
-	return d.Decode(mp)
+	if c.Defaults {
+		v, err := newDefaulter(c, ko.conf.Delim).fill(mp, reflect.TypeOf(o), path)
+		if err != nil {
+			return err
+		}
+		mp = v
+	}
+
+	if err := d.Decode(mp); err != nil {
+		return err
+	}
+
+	// Both checks run so that all the problems in the config are reported at once.
+	var strictErr, validErr error
+	if c.Strict || c.StrictUnset {
+		strictErr = newDefaulter(c, ko.conf.Delim).checkStrict(mp, reflect.TypeOf(o), path, c.Strict, c.StrictUnset)
+	}
+	if c.Validate {
+		validErr = ko.validateStruct(path, o, c)
+	}
+	if strictErr != nil && validErr != nil {
+		return errors.Join(strictErr, validErr)
+	}
+	if strictErr != nil {
+		return strictErr
+	}
+	return validErr
+}
+
+// defaultDecoderConfig returns the mapstructure config used to
+// unmarshal values if there's no custom DecoderConfig.
+func defaultDecoderConfig() *mapstructure.DecoderConfig {
+	return &mapstructure.DecoderConfig{
+		DecodeHook: mapstructure.ComposeDecodeHookFunc(
+			mapstructure.StringToTimeDurationHookFunc(),
+			textUnmarshalerHookFunc()),
+		Metadata:         nil,
+		WeaklyTypedInput: true,
+	}
 }
 
 // Delete removes all nested values from a given path.
 // Clears all keys/values if no path is specified.
 // Every empty, key on the path, is recursively deleted.
 func (ko *Koanf) Delete(path string) {
+	if ko.readOnly {
+		return
+	}
+
 	ko.mu.Lock()
-	defer ko.mu.Unlock()
+	cur := ko.st.Load()
 
-	// No path. Erase the entire map.
+	var st *state
 	if path == "" {
-		ko.confMap = make(map[string]any)
-		ko.confMapFlat = make(map[string]any)
-		ko.keyMap = make(KeyMap)
-		return
-	}
+		// No path. Erase the entire map.
+		st = newState()
+		st.loads = cur.loads
+		st.edits = cur.withEdit(edit{del: true})
+	} else {
+		// Does the path exist?
+		key := ko.key(cur, path)
+		p, ok := ko.lookup(cur, key)
+		if !ok {
+			ko.mu.Unlock()
+			return
+		}
 
-	// Does the path exist?
-	p, ok := ko.keyMap[path]
-	if !ok {
-		return
+		st = cur.clone()
+		st.confMap = maps.Copy(cur.confMap)
+		st.edits = cur.withEdit(edit{key: key, del: true})
+		maps.Delete(st.confMap, p)
+
+		// Delete the unresolved value and resolve the references again as
+		// they may have referred to the deleted key. Deleting can't create a cycle.
+		if ko.conf.Interpolate {
+			st.tplMap = maps.Copy(cur.tplMap)
+			maps.Delete(st.tplMap, p)
+			if mp, refs, err := interpolate(st.tplMap, ko.conf.Delim); err == nil {
+				st.confMap = mp
+				st.refs = refs
+			}
+		}
+
+		// Update the flattened version as well.
+		ko.flatten(st)
+		st.pruneOrigins()
+		st.pruneSensitive()
+		if ko.conf.Interpolate {
+			ko.markRefs(st)
+		}
 	}
-	maps.Delete(ko.confMap, p)
 
-	// Update the flattened version as well.
-	ko.confMapFlat, ko.keyMap = maps.Flatten(ko.confMap, nil, ko.conf.Delim)
-	ko.keyMap = populateKeyParts(ko.keyMap, ko.conf.Delim)
+	ko.st.Store(st)
+	ch := ko.newChange(cur, st)
+	ko.mu.Unlock()
+
+	ko.notify(ch)
 }
 
 // Get returns the raw, uncast any value of a given key path
@@ -332,16 +852,25 @@ func (ko *Koanf) Get(path string) any {
 		return ko.Raw()
 	}
 
-	// Does the path exist?
-	ko.mu.RLock()
-	defer ko.mu.RUnlock()
+	st := ko.st.Load()
+	return ko.get(st, ko.key(st, path))
+}
 
-	p, ok := ko.keyMap[path]
+// get returns a copy of the value of a given key path in the state.
+func (ko *Koanf) get(st *state, path string) any {
+	// Does the path exist?
+	p, ok := ko.lookup(st, path)
 	if !ok {
+		if path == "" {
+			return maps.Copy(st.confMap)
+		}
 		return nil
 	}
-	res := maps.Search(ko.confMap, p)
+	return copyValue(maps.Search(st.confMap, p))
+}
 
+// copyValue returns a deep copy of a value in the config map.
+func copyValue(res any) any {
 	// Non-reference types are okay to return directly.
 	// Other types are "copied" with maps.Copy or json.Marshal
 	// that change the numeric types to float64.
@@ -368,7 +897,8 @@ func (ko *Koanf) Get(path string) any {
 }
 
 // Slices returns a list of Koanf instances constructed out of a
-// []map[string]any interface at the given path.
+// []map[string]any interface at the given path. Like Cut(), the
+// instances have the same Conf and keep the keys under the path sensitive.
 func (ko *Koanf) Slices(path string) []*Koanf {
 	out := []*Koanf{}
 	if path == "" {
@@ -376,20 +906,21 @@ func (ko *Koanf) Slices(path string) []*Koanf {
 	}
 
 	// Does the path exist?
-	sl, ok := ko.Get(path).([]any)
+	st := ko.st.Load()
+	path = ko.key(st, path)
+	sl, ok := ko.get(st, path).([]any)
 	if !ok {
 		return out
 	}
 
-	for _, s := range sl {
+	for i, s := range sl {
 		mp, ok := s.(map[string]any)
 		if !ok {
 			continue
 		}
 
-		k := New(ko.conf.Delim)
-		_ = k.merge(mp, new(options))
-		out = append(out, k)
+		el := joinKey(path, strconv.Itoa(i), ko.conf.Delim)
+		out = append(out, ko.derive(st, mp, "Slices", ko.sensitiveUnder(st, el)))
 	}
 
 	return out
@@ -397,12 +928,55 @@ func (ko *Koanf) Slices(path string) []*Koanf {
 
 // Exists returns true if the given key path exists in the conf map.
 func (ko *Koanf) Exists(path string) bool {
-	ko.mu.RLock()
-	_, ok := ko.keyMap[path]
-	ko.mu.RUnlock()
+	st := ko.st.Load()
+	_, ok := ko.lookup(st, ko.key(st, path))
 	return ok
 }
 
+// Query returns the flattened keys (including the keys of maps, eg: `parent`
+// for `parent.child`) that match any of the given glob patterns and their
+// values, which are copies like the ones returned by Get(). In a pattern, `*`
+// matches a single key path part and `**` matches any number of parts, for
+// instance, `services.*.port` or `**.timeout`. See maps.MatchKey().
+func (ko *Koanf) Query(patterns ...string) map[string]any {
+	st := ko.st.Load()
+	out := make(map[string]any)
+	for k, p := range st.keyMap {
+		key := k
+		if ko.conf.CaseInsensitive {
+			key = strings.ToLower(k)
+		}
+
+		for _, pt := range patterns {
+			if ko.conf.CaseInsensitive {
+				pt = strings.ToLower(pt)
+			}
+			if maps.MatchKey(pt, key, ko.conf.Delim) {
+				out[k] = copyValue(maps.Search(st.confMap, p))
+				break
+			}
+		}
+	}
+	return out
+}
+
+// Eval evaluates a query expression, for instance, the JMESPath expression
+// "upstreams[?weight > `5`].host", against the config with Conf.Evaluator and
+// returns a read-only View of the result. The result can be read with the
+// View's typed getters, or if it's a map, as a new instance with View.Koanf().
+func (ko *Koanf) Eval(expr string) (View, error) {
+	if ko.conf.Evaluator == nil {
+		return View{}, errors.New("no evaluator set in Conf.Evaluator")
+	}
+
+	st := ko.st.Load()
+	res, err := ko.conf.Evaluator.Eval(expr, st.confMap)
+	if err != nil {
+		return View{}, fmt.Errorf("error evaluating %s: %w", expr, err)
+	}
+	return View{val: res, ok: res != nil, delim: ko.conf.Delim, fold: ko.conf.CaseInsensitive, ko: ko, st: st, eval: true}, nil
+}
+
 // MapKeys returns a sorted string list of keys in a map addressed by the
 // given path. If the path is not a map, an empty string slice is
 // returned.
@@ -433,37 +1007,195 @@ func (ko *Koanf) Delim() string {
 }
 
 func (ko *Koanf) merge(c map[string]any, opts *options) error {
-	ko.mu.Lock()
+	if ko.readOnly {
+		return ErrReadOnly
+	}
 
+	r, err := ko.resolve(c)
+	if err != nil {
+		return err
+	}
+	return ko.mergeResolved(r, opts)
+}
+
+// resolved is a config map with the values of deprecated keys moved to the
+// keys that replace them and the secret references resolved, to be merged.
+type resolved struct {
+	mp map[string]any
+
+	// Key paths of the resolved secrets and the deprecated keys that were moved.
+	secrets []string
+	deps    []Deprecation
+}
+
+// resolve moves the values of deprecated keys in c to the keys that replace
+// them and resolves the secret references in c in place.
+func (ko *Koanf) resolve(c map[string]any) (resolved, error) {
 	maps.IntfaceKeysToStrings(c)
-	if opts.merge != nil {
-		// Deep-copy confMap so the custom merge function can safely call
-		// ko.Get*() methods (which acquire a read lock) without deadlocking.
-		dest := maps.Copy(ko.confMap)
+	r := resolved{mp: c}
+
+	// Move the values of deprecated keys to the keys that replace them.
+	if len(ko.conf.Aliases) > 0 {
+		mp, d, err := ko.moveAliases(c)
+		if err != nil {
+			return r, err
+		}
+		r.mp, r.deps = mp, d
+	}
+
+	// Resolve secret references before locking as resolvers may
+	// make network calls.
+	secrets, err := ko.resolveSecrets(r.mp)
+	if err != nil {
+		return r, err
+	}
+	r.secrets = secrets
+	return r, nil
+}
+
+// mergeResolved merges a resolved config map into the current config and
+// reports its deprecated keys. Config maps that are copied from resolved
+// configs, for instance, by Cut(), are merged with it directly so that they
+// aren't resolved again.
+func (ko *Koanf) mergeResolved(r resolved, opts *options) error {
+	if ko.readOnly {
+		return ErrReadOnly
+	}
 
-		ko.mu.Unlock()
-		err := opts.merge(c, dest)
-		ko.mu.Lock()
+	ch, err := ko.apply(r.mp, r.secrets, opts)
+	if err != nil {
+		return err
+	}
+
+	ko.deprecated(r.deps)
+	ko.notify(ch)
+	return nil
+}
+
+// deprecated reports the deprecated keys to Conf.OnDeprecated.
+func (ko *Koanf) deprecated(deps []Deprecation) {
+	if ko.conf.OnDeprecated == nil {
+		return
+	}
+	for _, d := range deps {
+		ko.conf.OnDeprecated(d)
+	}
+}
+
+// apply merges the config map c into the current config and publishes
+// the resulting state. It returns the change notification for it.
+func (ko *Koanf) apply(c map[string]any, secrets []string, opts *options) (*change, error) {
+	ko.mu.Lock()
+	defer ko.mu.Unlock()
+	cur := ko.st.Load()
 
+	// The current state is never modified. Merge into a copy of the conf map.
+	// With interpolation, the unresolved config map is merged into as the
+	// references are resolved after merging.
+	var (
+		validate = ko.conf.Validator != nil || opts.validator != nil
+		dest     map[string]any
+	)
+	if ko.conf.Interpolate {
+		dest = maps.Copy(cur.tplMap)
+	} else {
+		dest = maps.Copy(cur.confMap)
+	}
+
+	if opts.prepare != nil {
+		mp, err := opts.prepare(dest)
 		if err != nil {
-			ko.mu.Unlock()
-			return err
+			return nil, err
+		}
+		if mp != nil {
+			c = mp
+		}
+	}
+
+	// Rename the incoming keys to the casing of the existing keys.
+	if ko.conf.CaseInsensitive {
+		c = foldCase(c, dest)
+	}
+
+	// Custom merge functions can safely call ko.Get*() methods as
+	// reads don't lock.
+	if opts.merge != nil {
+		if err := opts.merge(c, dest); err != nil {
+			return nil, err
 		}
-		ko.confMap = dest
+	} else if opts.sliceMerge != nil || len(opts.sliceRules) > 0 {
+		maps.MergeSlices(c, dest, ko.conf.Delim, opts.sliceMerge, opts.sliceRules...)
 	} else if ko.conf.StrictMerge {
-		if err := maps.MergeStrict(c, ko.confMap); err != nil {
-			ko.mu.Unlock()
-			return err
+		if err := maps.MergeStrict(c, dest); err != nil {
+			return nil, err
 		}
 	} else {
-		maps.Merge(c, ko.confMap)
+		maps.Merge(c, dest)
+	}
+
+	var (
+		res  = dest
+		refs map[string][]string
+	)
+	if ko.conf.Interpolate {
+		mp, r, err := interpolate(dest, ko.conf.Delim)
+		if err != nil {
+			return nil, err
+		}
+		res, refs = mp, r
+	}
+
+	if validate {
+		if err := ko.validate(res, opts.validator); err != nil {
+			return nil, err
+		}
+	}
+
+	st := cur.clone()
+	st.confMap = res
+	if opts.edit != nil {
+		st.edits = cur.withEdit(*opts.edit)
+	}
+	if ko.conf.Interpolate {
+		st.tplMap = dest
+		st.refs = refs
 	}
 
 	// Maintain a flattened version as well.
-	ko.confMapFlat, ko.keyMap = maps.Flatten(ko.confMap, nil, ko.conf.Delim)
-	ko.keyMap = populateKeyParts(ko.keyMap, ko.conf.Delim)
+	ko.flatten(st)
 
-	ko.mu.Unlock()
+	if ko.conf.Provenance {
+		ko.recordOrigins(st, c, opts)
+	}
+	if opts.sensitive || len(secrets) > 0 || len(st.sensitive) > 0 {
+		ko.markSensitive(st, c, secrets, opts.sensitive)
+	}
+	if ko.conf.Interpolate {
+		ko.markRefs(st)
+	}
+
+	// Publish the new state.
+	ko.st.Store(st)
+	return ko.newChange(cur, st), nil
+}
+
+// validate validates a conf map with the instance's validator, if one is
+// set, and the given validators.
+func (ko *Koanf) validate(mp map[string]any, vs ...Validator) error {
+	if ko.conf.Validator != nil {
+		if err := ko.conf.Validator.Validate(mp); err != nil {
+			return err
+		}
+	}
+
+	for _, v := range vs {
+		if v == nil {
+			continue
+		}
+		if err := v.Validate(mp); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
@@ -542,10 +1274,10 @@ func populateKeyParts(m KeyMap, delim string) KeyMap {
 		for i := range parts {
 			if i == 0 {
 				// On first iteration only use first part
-				nk = parts[i]
+				nk = maps.QuoteKey(parts[i], delim)
 			} else {
 				// If nk already contains a part (e.g. `parent`) append delim + `child`
-				nk += delim + parts[i]
+				nk += delim + maps.QuoteKey(parts[i], delim)
 			}
 			if _, ok := out[nk]; ok {
 				continue
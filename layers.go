@@ -0,0 +1,293 @@
+package koanf
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/knadh/koanf/maps"
+)
+
+// Layers is an ordered stack of named config layers (eg: defaults, file,
+// env, flags) on top of a Koanf instance. Every layer retains its own config
+// map and the Koanf instance holds the merged view of all the layers in the
+// order in which they were added, the last one having the highest precedence.
+//
+// Reloading or replacing a single layer recomputes the merged view from all the
+// layers, so keys that were removed from the layer's source disappear from
+// the Koanf instance unless another layer sets them. Changes made directly
+// to the Koanf instance, for instance, with Set(), are discarded on the
+// next recompute. The secret references in a layer are resolved, and its
+// deprecated keys reported, once when it's read and not on every recompute.
+type Layers struct {
+	ko     *Koanf
+	layers []*layer
+	mu     sync.Mutex
+}
+
+// layer is a single named layer in Layers.
+type layer struct {
+	name string
+	p    Provider
+	pa   Parser
+	opts []Option
+	mp   map[string]any
+
+	// The config map with the deprecated keys moved and the secrets
+	// resolved when the layer was read, which is merged on every recompute.
+	res resolved
+}
+
+// NewLayers returns a new layer stack that maintains its merged
+// view in the given Koanf instance. Any config already loaded in the
+// instance is discarded when the first layer is added.
+func NewLayers(ko *Koanf) *Layers {
+	return &Layers{ko: ko}
+}
+
+// Koanf returns the Koanf instance holding the merged view of the layers.
+func (l *Layers) Koanf() *Koanf {
+	return l.ko
+}
+
+// Add reads the config map from the given Provider (and Parser) like
+// Koanf.Load and adds it as a layer with the given name on top of the stack.
+// The options, for instance, a custom merge function, are applied every time
+// the layer is merged.
+func (l *Layers) Add(name string, p Provider, pa Parser, opts ...Option) error {
+	mp, err := read(p, pa)
+	if err != nil {
+		return err
+	}
+
+	// Secrets are resolved in place. Resolve a copy so that the layer's map stays intact.
+	res, err := l.ko.resolve(maps.Copy(mp))
+	if err != nil {
+		return fmt.Errorf("error merging layer %s: %w", name, err)
+	}
+
+	if err := l.add(&layer{name: name, p: p, pa: pa, opts: opts, mp: mp, res: res}); err != nil {
+		return err
+	}
+
+	l.ko.deprecated(res.deps)
+	return nil
+}
+
+// add adds the layer on top of the stack and recomputes the merged view.
+func (l *Layers) add(ly *layer) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.get(ly.name) != nil {
+		return fmt.Errorf("layer %s already exists", ly.name)
+	}
+
+	l.layers = append(l.layers, ly)
+	if err := l.rebuild(); err != nil {
+		l.layers = l.layers[:len(l.layers)-1]
+		return err
+	}
+
+	return nil
+}
+
+// Reload re-reads the named layer from its Provider and recomputes
+// the merged view. If reading or merging fails, the previous state of
+// the layer is retained.
+func (l *Layers) Reload(name string) error {
+	l.mu.Lock()
+	ly := l.get(name)
+	l.mu.Unlock()
+	if ly == nil {
+		return fmt.Errorf("layer %s not found", name)
+	}
+
+	mp, err := read(ly.p, ly.pa)
+	if err != nil {
+		return err
+	}
+
+	return l.Replace(name, mp)
+}
+
+// Replace replaces the config map of the named layer with the given map
+// and recomputes the merged view. If merging fails, the previous state of
+// the layer is retained.
+func (l *Layers) Replace(name string, mp map[string]any) error {
+	res, err := l.ko.resolve(maps.Copy(mp))
+	if err != nil {
+		return fmt.Errorf("error merging layer %s: %w", name, err)
+	}
+
+	if err := l.replace(name, mp, res); err != nil {
+		return err
+	}
+
+	l.ko.deprecated(res.deps)
+	return nil
+}
+
+// replace replaces the config map of the named layer and recomputes
+// the merged view.
+func (l *Layers) replace(name string, mp map[string]any, res resolved) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	ly := l.get(name)
+	if ly == nil {
+		return fmt.Errorf("layer %s not found", name)
+	}
+
+	oldMp, oldRes := ly.mp, ly.res
+	ly.mp, ly.res = mp, res
+	if err := l.rebuild(); err != nil {
+		ly.mp, ly.res = oldMp, oldRes
+		return err
+	}
+
+	return nil
+}
+
+// Remove removes the named layer from the stack and recomputes
+// the merged view.
+func (l *Layers) Remove(name string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for i, ly := range l.layers {
+		if ly.name != name {
+			continue
+		}
+
+		old := l.layers
+		l.layers = append(append([]*layer{}, l.layers[:i]...), l.layers[i+1:]...)
+		if err := l.rebuild(); err != nil {
+			l.layers = old
+			return err
+		}
+		return nil
+	}
+
+	return fmt.Errorf("layer %s not found", name)
+}
+
+// Names returns the names of the layers in the order of precedence,
+// lowest first.
+func (l *Layers) Names() []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	out := make([]string, 0, len(l.layers))
+	for _, ly := range l.layers {
+		out = append(out, ly.name)
+	}
+	return out
+}
+
+// Raw returns a copy of the config map of the named layer, or nil if
+// the layer doesn't exist.
+func (l *Layers) Raw(name string) map[string]any {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	ly := l.get(name)
+	if ly == nil {
+		return nil
+	}
+	return maps.Copy(ly.mp)
+}
+
+// Watch watches the named layer's Provider for changes if it implements
+// Watcher and reloads the layer on every change. cb, if not nil, is called
+// with the error of every reload or watch failure. The watch is stopped when
+// ctx is cancelled or the Provider's Unwatch() is called.
+func (l *Layers) Watch(ctx context.Context, name string, cb func(err error)) error {
+	l.mu.Lock()
+	ly := l.get(name)
+	l.mu.Unlock()
+	if ly == nil {
+		return fmt.Errorf("layer %s not found", name)
+	}
+
+	w, ok := ly.p.(Watcher)
+	if !ok {
+		return fmt.Errorf("provider of layer %s does not support watching", name)
+	}
+
+	return w.Watch(ctx, func(_ Event, err error) {
+		if err == nil {
+			err = l.Reload(name)
+		}
+		if cb != nil {
+			cb(err)
+		}
+	})
+}
+
+// get returns the named layer. It expects l.mu to be locked.
+func (l *Layers) get(name string) *layer {
+	for _, ly := range l.layers {
+		if ly.name == name {
+			return ly
+		}
+	}
+	return nil
+}
+
+// rebuild merges all the layers in order into a fresh config map
+// and swaps it into the Koanf instance. It expects l.mu to be locked.
+func (l *Layers) rebuild() error {
+	if l.ko.readOnly {
+		return ErrReadOnly
+	}
+
+	// Only the fully merged config is validated and not every
+	// intermediate layer.
+	conf := l.ko.conf
+	conf.Validator = nil
+
+	var (
+		n  = NewWithConf(conf)
+		vs []Validator
+	)
+	for _, ly := range l.layers {
+		o := newLoadOptions(ly.p, ly.pa, ly.opts)
+		if o.validator != nil {
+			vs = append(vs, o.validator)
+			o.validator = nil
+		}
+		if o.source == "" {
+			o.source = ly.name
+		}
+
+		// Merging retains references to the source maps and mutates them
+		// on subsequent merges. Merge a copy so that the layer's map stays
+		// intact. The layer is already resolved and its deprecated keys were
+		// reported when it was read.
+		r := ly.res
+		r.mp, r.deps = maps.Copy(r.mp), nil
+		if err := n.mergeResolved(r, o); err != nil {
+			return fmt.Errorf("error merging layer %s: %w", ly.name, err)
+		}
+	}
+
+	if err := l.ko.validate(n.st.Load().confMap, vs...); err != nil {
+		return err
+	}
+
+	l.ko.replace(n)
+	return nil
+}
+
+// replace replaces the config of the Koanf instance with that of n.
+func (ko *Koanf) replace(n *Koanf) {
+	ko.mu.Lock()
+	old := ko.st.Load()
+	st := n.st.Load()
+	ko.st.Store(st)
+	ch := ko.newChange(old, st)
+	ko.mu.Unlock()
+
+	ko.notify(ch)
+}
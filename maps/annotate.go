@@ -0,0 +1,73 @@
+package maps
+
+import (
+	"sort"
+	"strings"
+)
+
+// Annotation holds the order and the comments of the keys of a config map,
+// for the parsers that write annotated configs.
+type Annotation struct {
+	rank     map[string]int
+	comments map[string]string
+	delim    string
+}
+
+// NewAnnotation returns an Annotation that orders keys by the order of their
+// full delimited key paths in keys, with parents ordered by their first key,
+// and the keys that aren't in keys last. comments holds the comments of the
+// full key paths.
+func NewAnnotation(keys []string, comments map[string]string, delim string) *Annotation {
+	a := &Annotation{rank: make(map[string]int, len(keys)), comments: comments, delim: delim}
+	for i, k := range keys {
+		// Parents are ordered by their first key.
+		for {
+			if _, ok := a.rank[k]; !ok {
+				a.rank[k] = i
+			}
+			j := strings.LastIndex(k, delim)
+			if j < 0 || delim == "" {
+				break
+			}
+			k = k[:j]
+		}
+	}
+	return a
+}
+
+// Keys returns the keys of the map at the key path, given as its parts, in order.
+func (a *Annotation) Keys(mp map[string]any, path []string) []string {
+	out := make([]string, 0, len(mp))
+	for k := range mp {
+		out = append(out, k)
+	}
+	sort.Strings(out)
+
+	rank := make(map[string]int, len(out))
+	for _, k := range out {
+		r, ok := a.rank[strings.Join(append(path[:len(path):len(path)], k), a.delim)]
+		if !ok {
+			r = len(a.rank)
+		}
+		rank[k] = r
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		return rank[out[i]] < rank[out[j]]
+	})
+	return out
+}
+
+// Comment returns the comment of the key at the key path, given as its parts,
+// as `# ` comment lines prefixed with indent, each ending with a newline, or
+// "" if it has no comment.
+func (a *Annotation) Comment(path []string, indent string) string {
+	c := a.comments[strings.Join(path, a.delim)]
+	if c == "" {
+		return ""
+	}
+	var out strings.Builder
+	for _, l := range strings.Split(c, "\n") {
+		out.WriteString(strings.TrimRight(indent+"# "+l, " ") + "\n")
+	}
+	return out.String()
+}
@@ -0,0 +1,92 @@
+package maps
+
+import "strings"
+
+// SplitKey splits a delimited key path into its parts. A part that contains
+// the delimiter can be enclosed in double quotes, for instance,
+// `hosts."api.example.com".port` is ["hosts", "api.example.com", "port"].
+// In a quoted part, a double quote or a backslash is escaped with a backslash.
+// Quotes that don't enclose a whole part are part of the key.
+func SplitKey(key, delim string) []string {
+	if delim == "" {
+		return []string{key}
+	}
+
+	// Fast path for keys without quotes.
+	if !strings.Contains(key, `"`) {
+		return strings.Split(key, delim)
+	}
+
+	var out []string
+	for {
+		if part, n, ok := unquoteKey(key, delim); ok {
+			out = append(out, part)
+			if n == len(key) {
+				return out
+			}
+			key = key[n+len(delim):]
+			continue
+		}
+
+		part, rest, ok := strings.Cut(key, delim)
+		out = append(out, part)
+		if !ok {
+			return out
+		}
+		key = rest
+	}
+}
+
+// unquoteKey unquotes the quoted part at the beginning of the key path. It
+// returns the part and the length of the quoted part if it's followed by
+// the delimiter or is at the end of the key path.
+func unquoteKey(key, delim string) (string, int, bool) {
+	if !strings.HasPrefix(key, `"`) {
+		return "", 0, false
+	}
+
+	var b strings.Builder
+	for i := 1; i < len(key); i++ {
+		switch key[i] {
+		case '\\':
+			if i+1 < len(key) {
+				i++
+			}
+			b.WriteByte(key[i])
+		case '"':
+			n := i + 1
+			if n == len(key) || strings.HasPrefix(key[n:], delim) {
+				return b.String(), n, true
+			}
+			return "", 0, false
+		default:
+			b.WriteByte(key[i])
+		}
+	}
+	return "", 0, false
+}
+
+// JoinKey joins key parts into a delimited key path. Parts that contain the
+// delimiter (or begin with a double quote) are quoted so that SplitKey
+// splits the key path back into the same parts.
+func JoinKey(parts []string, delim string) string {
+	var b strings.Builder
+	for i, p := range parts {
+		if i > 0 {
+			b.WriteString(delim)
+		}
+		b.WriteString(QuoteKey(p, delim))
+	}
+	return b.String()
+}
+
+// QuoteKey quotes a single key part if it contains the delimiter
+// (or begins with a double quote). See SplitKey.
+func QuoteKey(part, delim string) string {
+	if delim == "" || (!strings.Contains(part, delim) && !strings.HasPrefix(part, `"`)) {
+		return part
+	}
+
+	r := strings.NewReplacer(`\`, `\\`, `"`, `\"`)
+	return `"` + r.Replace(part) + `"`
+}
@@ -6,6 +6,8 @@ package maps
 import (
 	"fmt"
 	"reflect"
+	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/mitchellh/copystructure"
@@ -19,6 +21,7 @@ import (
 // Use IntfaceKeysToStrings() to convert if necessary.
 //
 // eg: `{ "parent": { "child": 123 }}` becomes `{ "parent.child": 123 }`
+// Keys that contain the delimiter are quoted (see JoinKey).
 // In addition, it keeps track of and returns a map of the delimited keypaths with
 // a slice of key parts, for eg: { "parent.child": ["parent", "child"] }. This
 // parts list is used to remember the key path's original structure to
@@ -45,7 +48,7 @@ func flatten(m map[string]any, keys []string, delim string, out map[string]any,
 		case map[string]any:
 			// Empty map.
 			if len(cur) == 0 {
-				newKey := strings.Join(kp, delim)
+				newKey := JoinKey(kp, delim)
 				out[newKey] = val
 				keyMap[newKey] = kp
 				continue
@@ -54,16 +57,47 @@ func flatten(m map[string]any, keys []string, delim string, out map[string]any,
 			// It's a nested map. Flatten it recursively.
 			flatten(cur, kp, delim, out, keyMap)
 		default:
-			newKey := strings.Join(kp, delim)
+			newKey := JoinKey(kp, delim)
 			out[newKey] = val
 			keyMap[newKey] = kp
 		}
 	}
 }
 
+// FlattenStrings flattens a nested map into the flat string keys and values
+// of key-value stores like etcd or Consul. The key parts are joined with delim
+// as they are, without quoting. Strings are returned as they are, and bools
+// and numbers as text, which read back as strings are decoded to the same
+// values. Empty maps are skipped and other values, such as slices, return
+// an error as they can't be read back.
+//
+// eg: `{ "app": { "port": 80, "debug": true }}` becomes
+// `{ "app.port": "80", "app.debug": "true" }`
+func FlattenStrings(mp map[string]any, delim string) (map[string]string, error) {
+	flat, keys := Flatten(mp, nil, delim)
+
+	out := make(map[string]string, len(flat))
+	for k, v := range flat {
+		key := strings.Join(keys[k], delim)
+
+		switch v := v.(type) {
+		case map[string]any:
+			// Empty maps have no values.
+		case string:
+			out[key] = v
+		case bool, int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64:
+			out[key] = fmt.Sprintf("%v", v)
+		default:
+			return nil, fmt.Errorf("value of %s of type %T can't be written as a string", key, v)
+		}
+	}
+	return out, nil
+}
+
 // Unflatten takes a flattened key:value map (non-nested with delimited keys)
 // and returns a nested map where the keys are split into hierarchies by the given
 // delimiter. For instance, `parent.child.key: 1` to `{parent: {child: {key: 1}}}`
+// Quoted parts may contain the delimiter (see SplitKey).
 //
 // It's important to note that all nested maps should be
 // map[string]any and not map[any]any.
@@ -78,11 +112,7 @@ func Unflatten(m map[string]any, delim string) map[string]any {
 			next = out
 		)
 
-		if delim != "" {
-			keys = strings.Split(k, delim)
-		} else {
-			keys = []string{k}
-		}
+		keys = SplitKey(k, delim)
 
 		// Iterate through key parts, for eg:, parent.child.key
 		// will be ["parent", "child", "key"]
@@ -191,6 +221,8 @@ func mergeStrict(a, b map[string]any, fullKey string) error {
 
 // Delete removes the entry present at a given path, from the map. The path
 // is the key map slice, for eg:, parent.child.key -> [parent child key].
+// Elements of []any slices on the path are addressed by their index, and
+// deleting an element removes it from the slice.
 // Any empty, nested map on the path, is recursively deleted.
 //
 // It's important to note that all nested maps should be
@@ -210,12 +242,36 @@ func Delete(mp map[string]any, path []string) {
 			if len(nval) == 0 {
 				delete(mp, path[0])
 			}
+		case []any:
+			mp[path[0]] = deleteSlice(nval, path[1:])
 		}
 	}
 }
 
+// deleteSlice deletes the element or the nested value addressed by a path
+// that begins with an index from a slice and returns the slice.
+func deleteSlice(sl []any, path []string) []any {
+	i, ok := Index(sl, path[0])
+	if !ok {
+		return sl
+	}
+	if len(path) == 1 {
+		return append(sl[:i:i], sl[i+1:]...)
+	}
+
+	switch v := sl[i].(type) {
+	case map[string]any:
+		Delete(v, path[1:])
+	case []any:
+		sl[i] = deleteSlice(v, path[1:])
+	}
+	return sl
+}
+
 // Search recursively searches a map for a given path. The path is
 // the key map slice, for eg:, parent.child.key -> [parent child key].
+// Elements of []any slices on the path are addressed by their index,
+// for eg:, servers.0.host -> [servers 0 host].
 //
 // It's important to note that all nested maps should be
 // map[string]any and not map[any]any.
@@ -229,16 +285,123 @@ func Search(mp map[string]any, path []string) any {
 		switch m := next.(type) {
 		case map[string]any:
 			return Search(m, path[1:])
+		case []any:
+			return searchSlice(m, path[1:])
 		default:
 			return nil
-		} //
-		// It's important to note that all nested maps should be
-		// map[string]any and not map[any]any.
-		// Use IntfaceKeysToStrings() to convert if necessary.
+		}
+	}
+	return nil
+}
+
+// searchSlice searches a slice for a given path that begins with an index.
+func searchSlice(sl []any, path []string) any {
+	i, ok := Index(sl, path[0])
+	if !ok {
+		return nil
+	}
+	if len(path) == 1 {
+		return sl[i]
+	}
+
+	switch m := sl[i].(type) {
+	case map[string]any:
+		return Search(m, path[1:])
+	case []any:
+		return searchSlice(m, path[1:])
 	}
 	return nil
 }
 
+// Index parses a key path part as an index of the slice.
+// It returns false if it's not a number or is out of range.
+func Index(sl []any, part string) (int, bool) {
+	i, err := strconv.Atoi(part)
+	if err != nil || i < 0 || i >= len(sl) {
+		return 0, false
+	}
+	return i, true
+}
+
+// Change represents the change of the value of a flattened key between two maps.
+// Old is nil for added keys and New is nil for removed keys.
+type Change struct {
+	Key string
+	Old any
+	New any
+}
+
+// Changes represents the differences between two maps as lists of added,
+// removed, and changed flattened keys, each sorted by key.
+type Changes struct {
+	Added   []Change
+	Removed []Change
+	Changed []Change
+}
+
+// IsEmpty returns true if there are no differences.
+func (c Changes) IsEmpty() bool {
+	return len(c.Added) == 0 && len(c.Removed) == 0 && len(c.Changed) == 0
+}
+
+// Keys returns the sorted list of all added, removed, and changed keys.
+func (c Changes) Keys() []string {
+	out := make([]string, 0, len(c.Added)+len(c.Removed)+len(c.Changed))
+	for _, l := range [][]Change{c.Added, c.Removed, c.Changed} {
+		for _, ch := range l {
+			out = append(out, ch.Key)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+// Diff compares map a (old) with map b (new) and returns the keys that were added,
+// removed, or whose values changed in b, as flattened keys delimited by delim.
+// Values are compared with reflect.DeepEqual, so the same number with different
+// types (eg: int and float64) is a change. Note that there's no copying involved,
+// so the changes retain references to the values in the maps.
+//
+// eg: `{ "parent": { "a": 1, "b": 2 }}` and `{ "parent": { "b": 3, "c": 4 }}` produce
+// Added: parent.c, Removed: parent.a, and Changed: parent.b.
+//
+// It's important to note that all nested maps should be
+// map[string]any and not map[any]any.
+// Use IntfaceKeysToStrings() to convert if necessary.
+func Diff(a, b map[string]any, delim string) Changes {
+	var (
+		fa, _ = Flatten(a, nil, delim)
+		fb, _ = Flatten(b, nil, delim)
+		out   Changes
+	)
+
+	for k, av := range fa {
+		bv, ok := fb[k]
+		if !ok {
+			out.Removed = append(out.Removed, Change{Key: k, Old: av})
+			continue
+		}
+
+		if !reflect.DeepEqual(av, bv) {
+			out.Changed = append(out.Changed, Change{Key: k, Old: av, New: bv})
+		}
+	}
+
+	for k, bv := range fb {
+		if _, ok := fa[k]; !ok {
+			out.Added = append(out.Added, Change{Key: k, New: bv})
+		}
+	}
+
+	for _, l := range [][]Change{out.Added, out.Removed, out.Changed} {
+		sort.Slice(l, func(i, j int) bool {
+			return l[i].Key < l[j].Key
+		})
+	}
+
+	return out
+}
+
 // Copy returns a deep copy of a conf map.
 //
 // It's important to note that all nested maps should be
@@ -0,0 +1,227 @@
+package maps
+
+import (
+	"path"
+	"reflect"
+)
+
+// SliceMergeFunc merges the incoming slice src into the existing
+// slice dest and returns the merged slice.
+type SliceMergeFunc func(src, dest []any) []any
+
+// SliceRule merges the slices at the key paths that match Pattern
+// with Merge. See MatchKey for the pattern syntax.
+type SliceRule struct {
+	Pattern string
+	Merge   SliceMergeFunc
+}
+
+// SliceReplace replaces dest with src. This is the behaviour of Merge.
+func SliceReplace(src, dest []any) []any {
+	return src
+}
+
+// SliceAppend appends src to dest.
+func SliceAppend(src, dest []any) []any {
+	out := make([]any, 0, len(dest)+len(src))
+	out = append(out, dest...)
+	return append(out, src...)
+}
+
+// SlicePrepend prepends src to dest.
+func SlicePrepend(src, dest []any) []any {
+	out := make([]any, 0, len(dest)+len(src))
+	out = append(out, src...)
+	return append(out, dest...)
+}
+
+// SliceUnion appends src to dest and removes duplicate values,
+// retaining the first occurrence of every value.
+func SliceUnion(src, dest []any) []any {
+	out := make([]any, 0, len(dest)+len(src))
+	for _, v := range append(append([]any{}, dest...), src...) {
+		if indexOf(out, v) < 0 {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+// SliceMergeByIndex merges every element in src into the element at the
+// same index in dest. Maps are merged recursively with Merge and other
+// values are replaced. Extra elements in src are appended.
+func SliceMergeByIndex(src, dest []any) []any {
+	out := append([]any{}, dest...)
+	for i, v := range src {
+		if i >= len(out) {
+			out = append(out, v)
+			continue
+		}
+		out[i] = mergeElem(v, out[i])
+	}
+	return out
+}
+
+// SliceMergeByField returns a SliceMergeFunc for slices of maps that merges
+// every map in src into the map in dest that has the same value for the
+// identity field (eg: name or id) with Merge. Elements in src without a
+// matching element in dest are appended.
+func SliceMergeByField(field string) SliceMergeFunc {
+	return func(src, dest []any) []any {
+		out := append([]any{}, dest...)
+		for _, v := range src {
+			id, ok := fieldOf(v, field)
+			if !ok {
+				out = append(out, v)
+				continue
+			}
+
+			i := -1
+			for j, d := range out {
+				if did, ok := fieldOf(d, field); ok && reflect.DeepEqual(id, did) {
+					i = j
+					break
+				}
+			}
+
+			if i < 0 {
+				out = append(out, v)
+				continue
+			}
+			out[i] = mergeElem(v, out[i])
+		}
+		return out
+	}
+}
+
+// MergeSlices recursively merges map a into b (left to right) like Merge,
+// but merges the slices in a that are also slices in b with the SliceMergeFunc
+// of the first rule whose pattern matches the delimited key path of the slice,
+// or with def if there's none. If def is nil, slices are replaced.
+//
+// Like Merge, there's no copying involved, so map b will retain references to map a.
+func MergeSlices(a, b map[string]any, delim string, def SliceMergeFunc, rules ...SliceRule) {
+	mergeSlices(a, b, "", delim, def, rules)
+}
+
+func mergeSlices(a, b map[string]any, parent, delim string, def SliceMergeFunc, rules []SliceRule) {
+	for key, val := range a {
+		fullKey := QuoteKey(key, delim)
+		if parent != "" {
+			fullKey = parent + delim + fullKey
+		}
+
+		bVal, ok := b[key]
+		if !ok {
+			b[key] = val
+			continue
+		}
+
+		switch v := val.(type) {
+		case map[string]any:
+			if bv, ok := bVal.(map[string]any); ok {
+				mergeSlices(v, bv, fullKey, delim, def, rules)
+				continue
+			}
+
+		case []any:
+			if bv, ok := bVal.([]any); ok {
+				if fn := sliceMergeFunc(fullKey, delim, def, rules); fn != nil {
+					b[key] = fn(v, bv)
+					continue
+				}
+			}
+		}
+
+		b[key] = val
+	}
+}
+
+// SliceMerger returns a merge function for koanf.WithMergeFunc
+// that merges with MergeSlices.
+func SliceMerger(delim string, def SliceMergeFunc, rules ...SliceRule) func(src, dest map[string]any) error {
+	return func(src, dest map[string]any) error {
+		MergeSlices(src, dest, delim, def, rules...)
+		return nil
+	}
+}
+
+// MatchKey checks whether a delimited key path matches a glob pattern,
+// for instance, `servers.*.hosts` or `**.plugins`. In a pattern, `**`
+// matches any number of key path segments and other segments are matched
+// with path.Match, for instance, `*` or `*_token`. Parts that contain the
+// delimiter are quoted (see SplitKey).
+func MatchKey(pattern, key, delim string) bool {
+	return matchParts(SplitKey(pattern, delim), SplitKey(key, delim))
+}
+
+// matchParts matches the segments of a key path against the segments of a pattern.
+func matchParts(pattern, key []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			for i := 0; i <= len(key); i++ {
+				if matchParts(pattern[1:], key[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+
+		if len(key) == 0 {
+			return false
+		}
+		if ok, _ := path.Match(pattern[0], key[0]); !ok {
+			return false
+		}
+		pattern, key = pattern[1:], key[1:]
+	}
+
+	return len(key) == 0
+}
+
+// sliceMergeFunc returns the SliceMergeFunc for the slice at the key path.
+func sliceMergeFunc(key, delim string, def SliceMergeFunc, rules []SliceRule) SliceMergeFunc {
+	for _, r := range rules {
+		if MatchKey(r.Pattern, key, delim) {
+			return r.Merge
+		}
+	}
+	return def
+}
+
+// mergeElem merges the slice element src into dest if both are maps,
+// or returns src.
+func mergeElem(src, dest any) any {
+	s, ok := src.(map[string]any)
+	if !ok {
+		return src
+	}
+	d, ok := dest.(map[string]any)
+	if !ok {
+		return src
+	}
+
+	Merge(s, d)
+	return d
+}
+
+// fieldOf returns the value of the field in v if v is a map.
+func fieldOf(v any, field string) (any, bool) {
+	mp, ok := v.(map[string]any)
+	if !ok {
+		return nil, false
+	}
+	id, ok := mp[field]
+	return id, ok
+}
+
+// indexOf returns the index of the first value in s that is
+// deeply equal to v, or -1.
+func indexOf(s []any, v any) int {
+	for i, el := range s {
+		if reflect.DeepEqual(el, v) {
+			return i
+		}
+	}
+	return -1
+}
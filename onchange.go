@@ -0,0 +1,175 @@
+package koanf
+
+import (
+	"strings"
+
+	"github.com/knadh/koanf/maps"
+)
+
+// subscription is a change subscription registered with OnChange().
+type subscription struct {
+	prefix string
+	cb     func(old, new *Koanf)
+}
+
+// change is a pending change notification with the
+// states before and after the change.
+type change struct {
+	old *state
+	new *state
+}
+
+// OnChange registers a callback that is invoked when the value of any key
+// under the given key path prefix (or the key itself) is added, removed, or
+// changed by Load(), Set(), Delete(), Merge(), MergeAt(), or a Layers reload.
+// An empty prefix matches all keys. The callback receives read-only
+// snapshots (see Snapshot()) of the full config before and after the change.
+//
+// Callbacks are invoked synchronously after the change is committed and no
+// locks are held, so they can safely call methods on the instance. They are
+// invoked one at a time, in the order of the changes, even when the changes
+// are made concurrently: if a goroutine is already invoking callbacks, for
+// earlier changes, or it's a callback that made the change, that goroutine
+// invokes them for the change after the earlier ones, and the method that
+// made the change returns without waiting for them. OnChange is safe to
+// call concurrently. It returns a function that removes the subscription.
+func (ko *Koanf) OnChange(prefix string, cb func(old, new *Koanf)) func() {
+	s := &subscription{prefix: prefix, cb: cb}
+
+	ko.subsMu.Lock()
+	ko.subs = append(ko.subs, s)
+	ko.subsMu.Unlock()
+
+	return func() {
+		ko.subsMu.Lock()
+		defer ko.subsMu.Unlock()
+
+		for i, sub := range ko.subs {
+			if sub == s {
+				ko.subs = append(ko.subs[:i:i], ko.subs[i+1:]...)
+				break
+			}
+		}
+	}
+}
+
+// newChange queues a change notification for the old and new states if
+// there are change subscriptions and returns it, otherwise nil. It expects
+// ko.mu to be locked so that the notifications are queued in the order of
+// the changes.
+func (ko *Koanf) newChange(old, new *state) *change {
+	ko.subsMu.RLock()
+	n := len(ko.subs)
+	ko.subsMu.RUnlock()
+
+	if n == 0 {
+		return nil
+	}
+
+	ch := &change{old: old, new: new}
+	ko.notifyMu.Lock()
+	ko.pending = append(ko.pending, ch)
+	ko.notifyMu.Unlock()
+	return ch
+}
+
+// notify delivers the queued change notifications, including ch, in order,
+// unless another goroutine, or a callback up the stack, is delivering them.
+// It expects ko.mu to be unlocked.
+func (ko *Koanf) notify(ch *change) {
+	if ch == nil {
+		return
+	}
+
+	ko.notifyMu.Lock()
+	if ko.delivering {
+		ko.notifyMu.Unlock()
+		return
+	}
+	ko.delivering = true
+	ko.notifyMu.Unlock()
+
+	// If a callback panics, the next change delivers the rest.
+	done := false
+	defer func() {
+		if !done {
+			ko.notifyMu.Lock()
+			ko.delivering = false
+			ko.notifyMu.Unlock()
+		}
+	}()
+
+	for {
+		ko.notifyMu.Lock()
+		if len(ko.pending) == 0 {
+			ko.delivering, done = false, true
+			ko.notifyMu.Unlock()
+			return
+		}
+		next := ko.pending[0]
+		ko.pending[0] = nil
+		ko.pending = ko.pending[1:]
+		ko.notifyMu.Unlock()
+
+		ko.deliver(next)
+	}
+}
+
+// deliver invokes the callbacks of the subscriptions whose prefix
+// matches any of the keys changed by ch.
+func (ko *Koanf) deliver(ch *change) {
+	keys := maps.Diff(ch.old.confMap, ch.new.confMap, ko.conf.Delim).Keys()
+	if len(keys) == 0 {
+		return
+	}
+	if ko.conf.CaseInsensitive {
+		for i, k := range keys {
+			keys[i] = strings.ToLower(k)
+		}
+	}
+
+	ko.subsMu.RLock()
+	subs := make([]*subscription, len(ko.subs))
+	copy(subs, ko.subs)
+	ko.subsMu.RUnlock()
+
+	var oldKo, newKo *Koanf
+	for _, s := range subs {
+		prefix := s.prefix
+		if ko.conf.CaseInsensitive {
+			prefix = strings.ToLower(prefix)
+		}
+		if !matchesPrefix(keys, prefix, ko.conf.Delim) {
+			continue
+		}
+
+		// Create the old and new instances only once, if at least one subscription matches.
+		if oldKo == nil {
+			oldKo, newKo = ko.fromState(ch.old), ko.fromState(ch.new)
+		}
+		s.cb(oldKo, newKo)
+	}
+}
+
+// fromState returns a read-only instance with the same configuration
+// as ko and the given state.
+func (ko *Koanf) fromState(st *state) *Koanf {
+	n := &Koanf{conf: ko.conf, readOnly: true}
+	n.st.Store(st)
+	return n
+}
+
+// matchesPrefix checks whether any of the keys is the prefix key
+// itself or is nested under it.
+func matchesPrefix(keys []string, prefix, delim string) bool {
+	if prefix == "" {
+		return len(keys) > 0
+	}
+
+	for _, k := range keys {
+		if k == prefix || strings.HasPrefix(k, prefix+delim) {
+			return true
+		}
+	}
+	return false
+}
@@ -1,8 +1,29 @@
 package koanf
 
+import "github.com/knadh/koanf/maps"
+
 // options contains options to modify the behavior of Koanf.Load.
 type options struct {
-	merge func(a, b map[string]any) error
+	merge     func(a, b map[string]any) error
+	validator Validator
+	sensitive bool
+
+	// prepare returns the config map to merge instead of the given one,
+	// if it's not nil, from a copy of the current conf map.
+	prepare func(dest map[string]any) (map[string]any, error)
+
+	// Slice merge strategies. See WithSliceMerge.
+	sliceMerge maps.SliceMergeFunc
+	sliceRules []maps.SliceRule
+
+	// Change made with Set() to record for Edit().
+	edit *edit
+
+	// Description of the source of the config being merged
+	// for provenance tracking. See Conf.Provenance.
+	source   string
+	provider string
+	parser   string
 }
 
 // newOptions creates a new options instance.
@@ -12,6 +33,20 @@ func newOptions(opts []Option) *options {
 	return o
 }
 
+// newLoadOptions creates a new options instance for loading
+// from the given Provider and Parser.
+func newLoadOptions(p Provider, pa Parser, opts []Option) *options {
+	o := newOptions(opts)
+	o.provider = typeName(p)
+	if pa != nil {
+		o.parser = typeName(pa)
+	}
+	if sp, ok := p.(SensitiveProvider); ok && sp.Sensitive() {
+		o.sensitive = true
+	}
+	return o
+}
+
 // Option is a generic type used to modify the behavior of Koanf.Load.
 type Option func(*options)
 
@@ -31,3 +66,48 @@ func WithMergeFunc(merge func(src, dest map[string]any) error) Option {
 		o.merge = merge
 	}
 }
+
+// WithSource is an option to name the source of the config being loaded,
+// for instance, "defaults" or "/etc/app.yml". The name is recorded against
+// every key in the load for provenance tracking (see Conf.Provenance and Explain).
+// If unset, the Provider's type name is used.
+func WithSource(name string) Option {
+	return func(o *options) {
+		o.source = name
+	}
+}
+
+// WithValidator is an option to validate the config resulting from the
+// Koanf.Load before it replaces the existing config. It is applied in addition
+// to Conf.Validator, if one is set.
+func WithValidator(v Validator) Option {
+	return func(o *options) {
+		o.validator = v
+	}
+}
+
+// WithSensitive is an option to mark all the keys in the Koanf.Load
+// as sensitive so that their values are redacted in the output of
+// Sprint(), All() etc. See Conf.Sensitive.
+func WithSensitive() Option {
+	return func(o *options) {
+		o.sensitive = true
+	}
+}
+
+// WithSliceMerge is an option to merge the slices in the Koanf.Load with
+// the existing slices at the same key paths using the strategy def instead
+// of replacing them, for instance, maps.SliceAppend or maps.SliceMergeByField("name").
+// The rules apply different strategies to the slices at the key paths that
+// match their patterns, for instance, `plugins` or `servers.*.hosts`. The
+// first matching rule is applied, and if none match, def. If def is nil,
+// the slices that don't match a rule are replaced. Maps are merged as usual.
+//
+// WithSliceMerge overrides Conf.StrictMerge. It is ignored if
+// WithMergeFunc is used. See maps.MergeSlices.
+func WithSliceMerge(def maps.SliceMergeFunc, rules ...maps.SliceRule) Option {
+	return func(o *options) {
+		o.sliceMerge = def
+		o.sliceRules = append(o.sliceRules, rules...)
+	}
+}
@@ -0,0 +1,141 @@
+package koanf
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/knadh/koanf/maps"
+)
+
+// Origin represents a single value contributed to a key by a Load()
+// or another merge operation such as Set() or Merge().
+type Origin struct {
+	// Source is the name given to the load with WithSource(). If it's not
+	// set, it is the same as Provider.
+	Source string
+
+	// Provider is the type name of the Provider (eg: *file.File) or the name
+	// of the method (eg: Set, Merge) that contributed the value.
+	Provider string
+
+	// Parser is the type name of the Parser, if one was used.
+	Parser string
+
+	// Order is the sequence number of the merge on the Koanf instance, starting at 1.
+	Order int
+
+	// Value is the value that was contributed.
+	Value any
+}
+
+// Explanation is the provenance of a key's value returned by Explain().
+type Explanation struct {
+	// Key is the flattened key path.
+	Key string
+
+	// Value is the effective value of the key in the config map.
+	Value any
+
+	// Origins is the stack of values contributed to the key, in load order.
+	Origins []Origin
+
+	// Winner is the index of the origin in Origins that the effective value
+	// came from. It is -1 if the value doesn't match any of the origins,
+	// for instance, when a custom merge function combined them.
+	Winner int
+}
+
+// Explain returns the provenance of a flattened key path: every value that was
+// contributed to it by Load() (and Set(), Merge() etc.) in order, and which one
+// is in effect. It requires Conf.Provenance to be set. If the key doesn't
+// exist or there is no provenance for it, Origins is empty and Winner is -1.
+func (ko *Koanf) Explain(path string) Explanation {
+	st := ko.st.Load()
+	path = ko.key(st, path)
+	out := Explanation{
+		Key:    path,
+		Value:  st.confMapFlat[path],
+		Winner: -1,
+	}
+
+	org := st.origins[path]
+	if len(org) == 0 {
+		return out
+	}
+
+	out.Origins = make([]Origin, len(org))
+	copy(out.Origins, org)
+
+	// The last origin that matches the effective value is the winner.
+	for i := len(org) - 1; i >= 0; i-- {
+		if reflect.DeepEqual(org[i].Value, out.Value) {
+			out.Winner = i
+			break
+		}
+	}
+
+	return out
+}
+
+// recordOrigins records the origin of every flattened key in the
+// given (incoming) config map in an unpublished state.
+func (ko *Koanf) recordOrigins(st *state, c map[string]any, opts *options) {
+	st.loads++
+
+	src := opts.source
+	if src == "" {
+		src = opts.provider
+	}
+
+	fl, _ := maps.Flatten(c, nil, ko.conf.Delim)
+	for k, v := range fl {
+		// The slices are shared with previous states. Clip them so
+		// that appending always copies.
+		org := st.origins[k]
+		st.origins[k] = append(org[:len(org):len(org)], Origin{
+			Source:   src,
+			Provider: opts.provider,
+			Parser:   opts.parser,
+			Order:    st.loads,
+			Value:    v,
+		})
+	}
+
+	st.pruneOrigins()
+}
+
+// pruneOrigins removes the origins of keys that no longer exist
+// in the config map, for instance, a key that was deleted, or whose parent
+// was overwritten by a non-map value.
+func (st *state) pruneOrigins() {
+	for k := range st.origins {
+		if _, ok := st.confMapFlat[k]; !ok {
+			delete(st.origins, k)
+		}
+	}
+}
+
+// cutOrigins returns the origins of the keys under the given path
+// with the path trimmed from the keys.
+func cutOrigins(origins map[string][]Origin, path, delim string) map[string][]Origin {
+	out := make(map[string][]Origin)
+	prefix := path + delim
+	for k, org := range origins {
+		if path != "" {
+			if !strings.HasPrefix(k, prefix) {
+				continue
+			}
+			k = strings.TrimPrefix(k, prefix)
+		}
+
+		out[k] = make([]Origin, len(org))
+		copy(out[k], org)
+	}
+	return out
+}
+
+// typeName returns the type name of a Provider or Parser, eg: *file.File.
+func typeName(v any) string {
+	return fmt.Sprintf("%T", v)
+}
@@ -0,0 +1,107 @@
+package dotenv
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/joho/godotenv"
+	"github.com/knadh/koanf/maps"
+)
+
+// MarshalAnnotated marshals the config map like Marshal, writing the keys in
+// the order of the full key paths in keys with the comments above them.
+// Nested keys are flattened with the parser's delimiter, or delim if it
+// doesn't have one. Slices can't be written in a form that's read back as the
+// same values, so they are written as commented out example lines: slices of
+// values as comma separated lists, and other slices as their elements
+// addressed by their index.
+func (p *DotEnv) MarshalAnnotated(o map[string]any, keys []string, comments map[string]string, delim string) ([]byte, error) {
+	a := maps.NewAnnotation(keys, comments, delim)
+
+	sep := p.delim
+	if sep == "" {
+		sep = delim
+	}
+
+	var buf bytes.Buffer
+	if err := p.write(&buf, a, o, nil, sep, ""); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// write writes the keys of the map at the path, prefixing their lines with prefix.
+func (p *DotEnv) write(buf *bytes.Buffer, a *maps.Annotation, mp map[string]any, path []string, sep, prefix string) error {
+	for _, k := range a.Keys(mp, path) {
+		if err := p.writeValue(buf, a, mp[k], append(path[:len(path):len(path)], k), sep, prefix); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// writeValue writes the value at the path, prefixing its lines with prefix.
+func (p *DotEnv) writeValue(buf *bytes.Buffer, a *maps.Annotation, v any, path []string, sep, prefix string) error {
+	if m, ok := v.(map[string]any); ok {
+		if len(m) > 0 {
+			buf.WriteString(a.Comment(path, ""))
+		}
+		return p.write(buf, a, m, path, sep, prefix)
+	}
+
+	// The parser reads a comma separated list as a single string, and
+	// the elements of a slice addressed by their index as a map, so
+	// neither is unmarshalled back into a slice. They are commented out.
+	if r := reflect.ValueOf(v); r.Kind() == reflect.Slice || r.Kind() == reflect.Array {
+		prefix = "# "
+
+		vals := make([]string, 0, r.Len())
+		for i := 0; i < r.Len(); i++ {
+			e := r.Index(i).Interface()
+			if isComposite(e) {
+				vals = nil
+				break
+			}
+			vals = append(vals, fmt.Sprint(e))
+		}
+
+		if vals == nil {
+			buf.WriteString(a.Comment(path, ""))
+			for i := 0; i < r.Len(); i++ {
+				ep := append(path[:len(path):len(path)], strconv.Itoa(i))
+				if err := p.writeValue(buf, a, r.Index(i).Interface(), ep, sep, prefix); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+		v = strings.Join(vals, ",")
+	}
+
+	val := fmt.Sprint(v)
+	key := strings.Join(path, sep)
+	if sourceKey, found := p.reverseCB[key]; found {
+		key = sourceKey
+	}
+
+	out, err := godotenv.Marshal(map[string]string{key: val})
+	if err != nil {
+		return err
+	}
+	buf.WriteString(a.Comment(path, ""))
+	buf.WriteString(prefix + out + "\n")
+	return nil
+}
+
+// isComposite checks whether the value is a map or a slice.
+func isComposite(v any) bool {
+	if _, ok := v.(map[string]any); ok {
+		return true
+	}
+	k := reflect.ValueOf(v).Kind()
+	return k == reflect.Slice || k == reflect.Array
+}
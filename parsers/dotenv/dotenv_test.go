@@ -304,3 +304,58 @@ func TestParserEnvWithValue(t *testing.T) {
 		})
 	}
 }
+
+func TestDotEnv_MarshalAnnotated(t *testing.T) {
+	var (
+		mp = map[string]any{
+			"name":    "my app",
+			"tags":    []any{"a", "b"},
+			"db":      map[string]any{"port": int64(5432), "host": "localhost"},
+			"servers": []any{map[string]any{"port": int64(80), "host": ""}},
+			"extra":   map[string]any{},
+			"z":       true,
+		}
+		keys = []string{
+			"name", "tags", "db.port", "db.host", "db",
+			"servers.0.port", "servers.0.host", "servers", "extra",
+		}
+		comments = map[string]string{
+			"name":           "Name of the app.",
+			"db":             "Database.",
+			"db.host":        "Host.",
+			"servers":        "Servers.",
+			"servers.0.host": "Host to listen on.",
+			"extra":          "Not written.",
+		}
+	)
+
+	out, err := Parser().MarshalAnnotated(mp, keys, comments, ".")
+	require.NoError(t, err)
+	// Slices are commented out.
+	assert.Equal(t, `# Name of the app.
+name="my app"
+# tags="a,b"
+# Database.
+db.port=5432
+# Host.
+db.host="localhost"
+# Servers.
+# servers.0.port=80
+# Host to listen on.
+# servers.0.host=""
+z="true"
+`, string(out))
+
+	// The parser's delimiter is used to flatten keys.
+	p := ParserEnv("", "_", nil)
+	out, err = p.MarshalAnnotated(mp, keys, nil, ".")
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(string(out), "name=\"my app\"\n# tags=\"a,b\"\ndb_port=5432\n"))
+
+	// The commented out slices are not read back.
+	res, err := p.Unmarshal(out)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"host": "localhost", "port": "5432"}, res["db"])
+	assert.NotContains(t, res, "tags")
+	assert.NotContains(t, res, "servers")
+}
@@ -4,7 +4,7 @@ go 1.23.0
 
 require (
 	github.com/joho/godotenv v1.5.1
-	github.com/knadh/koanf/maps v0.1.2
+	github.com/knadh/koanf/maps v0.2.0
 	github.com/stretchr/testify v1.8.4
 )
 
@@ -0,0 +1,160 @@
+package hcl
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/knadh/koanf/maps"
+)
+
+// annotation holds the order and the comments of the keys of a config map.
+type annotation struct {
+	*maps.Annotation
+	delim string
+}
+
+// MarshalAnnotated marshals the config map to HCL, writing the keys in the
+// order of the full key paths in keys with the comments above them. Maps are
+// written as blocks and slices of maps as repeated blocks, which are read back
+// as maps with flattenSlices.
+func (p *HCL) MarshalAnnotated(o map[string]any, keys []string, comments map[string]string, delim string) ([]byte, error) {
+	var (
+		a   = &annotation{maps.NewAnnotation(keys, comments, delim), delim}
+		buf bytes.Buffer
+	)
+	if err := a.writeBody(&buf, o, nil, ""); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// writeBody writes the keys of the map at the path as attributes and blocks.
+func (a *annotation) writeBody(buf *bytes.Buffer, mp map[string]any, path []string, indent string) error {
+	for i, k := range a.Keys(mp, path) {
+		var (
+			p   = append(path[:len(path):len(path)], k)
+			key = quoteKey(k)
+		)
+
+		blocks, ok := toBlocks(mp[k])
+		if !ok {
+			val, err := marshalValue(mp[k])
+			if err != nil {
+				return fmt.Errorf("error encoding %s: %w", strings.Join(p, a.delim), err)
+			}
+			buf.WriteString(a.Comment(p, indent))
+			buf.WriteString(indent + key + " = " + val + "\n")
+			continue
+		}
+
+		if i > 0 {
+			buf.WriteString("\n")
+		}
+		buf.WriteString(a.Comment(p, indent))
+		for j, b := range blocks {
+			bp := p
+			if _, isMap := mp[k].(map[string]any); !isMap {
+				bp = append(p[:len(p):len(p)], strconv.Itoa(j))
+			}
+			buf.WriteString(indent + key + " {\n")
+			if err := a.writeBody(buf, b, bp, indent+"  "); err != nil {
+				return err
+			}
+			buf.WriteString(indent + "}\n")
+		}
+	}
+
+	return nil
+}
+
+// toBlocks returns the maps of the blocks that the value is written as:
+// the map itself, or the maps in a non-empty slice of maps.
+func toBlocks(v any) ([]map[string]any, bool) {
+	switch t := v.(type) {
+	case map[string]any:
+		return []map[string]any{t}, true
+
+	case []map[string]any:
+		return t, len(t) > 0
+
+	case []any:
+		out := make([]map[string]any, 0, len(t))
+		for _, e := range t {
+			m, ok := e.(map[string]any)
+			if !ok {
+				return nil, false
+			}
+			out = append(out, m)
+		}
+		return out, len(out) > 0
+	}
+	return nil, false
+}
+
+// marshalValue returns the HCL representation of a value in an attribute.
+func marshalValue(v any) (string, error) {
+	switch t := v.(type) {
+	case string:
+		return strconv.Quote(t), nil
+	case bool:
+		return strconv.FormatBool(t), nil
+	case map[string]any:
+		keys := make([]string, 0, len(t))
+		for k := range t {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		s := make([]string, len(keys))
+		for i, k := range keys {
+			val, err := marshalValue(t[k])
+			if err != nil {
+				return "", err
+			}
+			s[i] = quoteKey(k) + " = " + val
+		}
+		return "{" + strings.Join(s, ", ") + "}", nil
+	}
+
+	r := reflect.ValueOf(v)
+	switch r.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(r.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(r.Uint(), 10), nil
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(r.Float(), 'f', -1, 64), nil
+	case reflect.String:
+		return strconv.Quote(r.String()), nil
+	case reflect.Slice, reflect.Array:
+		s := make([]string, r.Len())
+		for i := range s {
+			val, err := marshalValue(r.Index(i).Interface())
+			if err != nil {
+				return "", err
+			}
+			s[i] = val
+		}
+		return "[" + strings.Join(s, ", ") + "]", nil
+	}
+
+	return "", fmt.Errorf("unsupported type %T", v)
+}
+
+// quoteKey quotes the key if it's not an identifier.
+func quoteKey(k string) string {
+	for i, c := range k {
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c == '_' ||
+			i > 0 && (c >= '0' && c <= '9' || c == '-')) {
+			return strconv.Quote(k)
+		}
+	}
+	if k == "" {
+		return `""`
+	}
+	return k
+}
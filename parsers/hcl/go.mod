@@ -4,6 +4,7 @@ go 1.23.0
 
 require (
 	github.com/hashicorp/hcl v1.0.0
+	github.com/knadh/koanf/maps v0.2.0
 	github.com/stretchr/testify v1.8.4
 )
 
@@ -11,6 +12,8 @@ require (
 	github.com/davecgh/go-spew v1.1.1 // indirect
 	github.com/kr/pretty v0.2.1 // indirect
 	github.com/kr/text v0.2.0 // indirect
+	github.com/mitchellh/copystructure v1.2.0 // indirect
+	github.com/mitchellh/reflectwalk v1.0.2 // indirect
 	github.com/pmezard/go-difflib v1.0.0 // indirect
 	gopkg.in/check.v1 v1.0.0-20190902080502-41f04d3bba15 // indirect
 	gopkg.in/yaml.v3 v3.0.1 // indirect
@@ -152,3 +152,72 @@ func TestHCL_Marshal(t *testing.T) {
 		})
 	}
 }
+
+func TestHCL_MarshalAnnotated(t *testing.T) {
+	var (
+		mp = map[string]any{
+			"name":    "app",
+			"ratio":   0.5,
+			"tags":    []any{"a", "b"},
+			"db":      map[string]any{"port": int64(5432), "host": "localhost"},
+			"servers": []any{map[string]any{"port": int64(80)}, map[string]any{"port": int64(81)}},
+			"labels":  map[string]any{"my key": "x"},
+			"empty":   []any{},
+			"z":       true,
+		}
+		keys = []string{
+			"name", "ratio", "tags", "db.port", "db.host", "db",
+			"servers.0.port", "servers", "labels", "empty",
+		}
+		comments = map[string]string{
+			"name":           "Name of the app.",
+			"db":             "Database.",
+			"db.host":        "Host.",
+			"servers":        "Servers.",
+			"servers.1.port": "Port.",
+		}
+	)
+
+	out, err := Parser(true).MarshalAnnotated(mp, keys, comments, ".")
+	assert.Nil(t, err)
+	assert.Equal(t, `# Name of the app.
+name = "app"
+ratio = 0.5
+tags = ["a", "b"]
+
+# Database.
+db {
+  port = 5432
+  # Host.
+  host = "localhost"
+}
+
+# Servers.
+servers {
+  port = 80
+}
+servers {
+  # Port.
+  port = 81
+}
+
+labels {
+  "my key" = "x"
+}
+empty = []
+z = true
+`, string(out))
+
+	res, err := Parser(true).Unmarshal(out)
+	assert.Nil(t, err)
+	assert.Equal(t, "app", res["name"])
+	assert.Equal(t, 0.5, res["ratio"])
+	assert.Equal(t, []any{"a", "b"}, res["tags"])
+	assert.Equal(t, map[string]any{"port": 5432, "host": "localhost"}, res["db"])
+	assert.Equal(t, []map[string]any{{"port": 80}, {"port": 81}}, res["servers"])
+	assert.Equal(t, map[string]any{"my key": "x"}, res["labels"])
+	assert.Equal(t, true, res["z"])
+
+	_, err = Parser(true).MarshalAnnotated(map[string]any{"a": nil}, nil, nil, ".")
+	assert.NotNil(t, err)
+}
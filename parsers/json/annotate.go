@@ -0,0 +1,80 @@
+package json
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/knadh/koanf/maps"
+)
+
+// annotation holds the order of the keys of a config map.
+type annotation struct {
+	*maps.Annotation
+	delim string
+}
+
+// MarshalAnnotated marshals the config map indented, writing the keys in
+// the order of the full key paths in keys. JSON doesn't have comments,
+// so the comments are not written.
+func (p *JSON) MarshalAnnotated(o map[string]any, keys []string, _ map[string]string, delim string) ([]byte, error) {
+	var (
+		a = &annotation{maps.NewAnnotation(keys, nil, delim), delim}
+		e = &editor{indent: "  "}
+	)
+
+	var buf bytes.Buffer
+	if err := a.write(&buf, e, o, nil, ""); err != nil {
+		return nil, err
+	}
+	buf.WriteString("\n")
+	return buf.Bytes(), nil
+}
+
+// write writes the value at the path, prefixing the lines after
+// the first with prefix.
+func (a *annotation) write(buf *bytes.Buffer, e *editor, v any, path []string, prefix string) error {
+	ind := prefix + e.indent
+
+	if mp, ok := toMap(v); ok && len(mp) > 0 {
+		buf.WriteString("{")
+		for i, k := range a.Keys(mp, path) {
+			if i > 0 {
+				buf.WriteString(",")
+			}
+			key, err := e.marshal(k, "")
+			if err != nil {
+				return err
+			}
+			buf.WriteString("\n" + ind + key + ": ")
+			if err := a.write(buf, e, mp[k], append(path[:len(path):len(path)], k), ind); err != nil {
+				return err
+			}
+		}
+		buf.WriteString("\n" + prefix + "}")
+		return nil
+	}
+
+	if sl, ok := toSlice(v); ok && len(sl) > 0 {
+		buf.WriteString("[")
+		for i, el := range sl {
+			if i > 0 {
+				buf.WriteString(",")
+			}
+			buf.WriteString("\n" + ind)
+			if err := a.write(buf, e, el, append(path[:len(path):len(path)], strconv.Itoa(i)), ind); err != nil {
+				return err
+			}
+		}
+		buf.WriteString("\n" + prefix + "]")
+		return nil
+	}
+
+	s, err := e.marshal(v, prefix)
+	if err != nil {
+		return fmt.Errorf("error encoding %s: %w", strings.Join(path, a.delim), err)
+	}
+	buf.WriteString(s)
+	return nil
+}
@@ -0,0 +1,396 @@
+package json
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// node is a value in a JSON document with its byte offsets.
+type node struct {
+	start, end int
+
+	obj     bool
+	members []member
+
+	arr   bool
+	elems []*node
+}
+
+// member is a key and its value in a JSON object.
+type member struct {
+	key   string
+	start int
+	val   *node
+}
+
+// splice replaces the bytes between start and end with text.
+type splice struct {
+	start, end int
+	text       string
+}
+
+// editor applies a config map to a JSON document.
+type editor struct {
+	b       []byte
+	dec     *json.Decoder
+	indent  string
+	splices []splice
+}
+
+// Edit returns the JSON document b updated to represent the given config map.
+// Unlike Marshal, key order and the formatting of the values that didn't
+// change are preserved. Keys that are not in the map are removed from the
+// document and new keys are added to the end of their objects with the
+// document's indentation.
+func (p *JSON) Edit(b []byte, o map[string]any) ([]byte, error) {
+	if len(bytes.TrimSpace(b)) == 0 {
+		return p.Marshal(o)
+	}
+
+	e := &editor{
+		b:      b,
+		dec:    json.NewDecoder(bytes.NewReader(b)),
+		indent: indent(b),
+	}
+	n, err := e.parse()
+	if err != nil {
+		return nil, err
+	}
+	if err := e.edit(n, o); err != nil {
+		return nil, err
+	}
+	return e.apply(), nil
+}
+
+// parse parses the next value in the document.
+func (e *editor) parse() (*node, error) {
+	n := &node{start: e.skip(int(e.dec.InputOffset()))}
+
+	tok, err := e.dec.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	switch tok {
+	case json.Delim('{'):
+		n.obj = true
+		for e.dec.More() {
+			start := e.skip(int(e.dec.InputOffset()))
+			k, err := e.dec.Token()
+			if err != nil {
+				return nil, err
+			}
+			v, err := e.parse()
+			if err != nil {
+				return nil, err
+			}
+			n.members = append(n.members, member{key: k.(string), start: start, val: v})
+		}
+		if _, err := e.dec.Token(); err != nil {
+			return nil, err
+		}
+
+	case json.Delim('['):
+		n.arr = true
+		for e.dec.More() {
+			v, err := e.parse()
+			if err != nil {
+				return nil, err
+			}
+			n.elems = append(n.elems, v)
+		}
+		if _, err := e.dec.Token(); err != nil {
+			return nil, err
+		}
+	}
+
+	n.end = int(e.dec.InputOffset())
+	return n, nil
+}
+
+// skip returns the offset of the next token after i, skipping
+// whitespace and separators.
+func (e *editor) skip(i int) int {
+	for i < len(e.b) && strings.IndexByte(" \t\r\n,:", e.b[i]) >= 0 {
+		i++
+	}
+	return i
+}
+
+// edit records the splices that update the node to represent the value v.
+func (e *editor) edit(n *node, v any) error {
+	var cur any
+	if err := json.Unmarshal(e.b[n.start:n.end], &cur); err == nil && equal(cur, v) {
+		return nil
+	}
+
+	if mp, ok := toMap(v); ok && n.obj {
+		return e.editObject(n, mp)
+	}
+	if sl, ok := toSlice(v); ok && n.arr && len(n.elems) > 0 && len(sl) > 0 {
+		return e.editArray(n, sl)
+	}
+	return e.replace(n, v)
+}
+
+// editObject records the splices that update the members of an
+// object node to represent the map.
+func (e *editor) editObject(n *node, mp map[string]any) error {
+	var (
+		keys = make(map[string]bool, len(mp))
+		last = -1
+	)
+	for i, m := range n.members {
+		if _, ok := mp[m.key]; ok {
+			last = i
+		}
+	}
+
+	// Objects without any remaining members are rewritten.
+	if last < 0 {
+		return e.replace(n, mp)
+	}
+
+	// Removed members are cut along with the comma before them, or
+	// after them if they are at the beginning of the object.
+	for i, m := range n.members {
+		v, ok := mp[m.key]
+		if ok && !keys[m.key] {
+			if len(keys) == 0 && i > 0 {
+				e.splices = append(e.splices, splice{start: n.members[0].start, end: m.start})
+			}
+			keys[m.key] = true
+			if err := e.edit(m.val, v); err != nil {
+				return err
+			}
+			continue
+		}
+		if len(keys) > 0 {
+			e.splices = append(e.splices, splice{start: n.members[i-1].val.end, end: m.val.end})
+		}
+	}
+
+	// New members are added after the last member.
+	var (
+		sep    = e.sep(n, n.members[0].start, n.members[0].val.end)
+		prefix = sep[strings.LastIndexByte(sep, '\n')+1:]
+		colon  = ":"
+	)
+	if e.indent != "" {
+		colon = ": "
+	}
+	for _, k := range sortedKeys(mp) {
+		if keys[k] {
+			continue
+		}
+
+		key, err := e.marshal(k, "")
+		if err != nil {
+			return err
+		}
+		val, err := e.marshal(mp[k], prefix)
+		if err != nil {
+			return fmt.Errorf("error encoding %s: %w", k, err)
+		}
+		pos := n.members[last].val.end
+		e.splices = append(e.splices, splice{start: pos, end: pos, text: "," + sep + key + colon + val})
+	}
+	return nil
+}
+
+// editArray records the splices that update the elements of an array node
+// to represent the slice.
+func (e *editor) editArray(n *node, sl []any) error {
+	for i, v := range sl {
+		if i < len(n.elems) {
+			if err := e.edit(n.elems[i], v); err != nil {
+				return err
+			}
+		}
+	}
+
+	last := n.elems[len(n.elems)-1]
+	if len(sl) < len(n.elems) {
+		e.splices = append(e.splices, splice{start: n.elems[len(sl)-1].end, end: last.end})
+		return nil
+	}
+
+	var (
+		sep    = e.sep(n, n.elems[0].start, n.elems[0].end)
+		prefix = sep[strings.LastIndexByte(sep, '\n')+1:]
+	)
+	for _, v := range sl[len(n.elems):] {
+		val, err := e.marshal(v, prefix)
+		if err != nil {
+			return err
+		}
+		e.splices = append(e.splices, splice{start: last.end, end: last.end, text: "," + sep + val})
+	}
+	return nil
+}
+
+// sep returns the whitespace that separates the members or elements of the
+// node, given the offsets of the first one. It's the whitespace after the
+// first comma, or the whitespace before the first member or element.
+func (e *editor) sep(n *node, start, end int) string {
+	if i := e.skip(end); i < n.end-1 {
+		return strings.Replace(string(e.b[end:i]), ",", "", 1)
+	}
+	return string(e.b[n.start+1 : start])
+}
+
+// replace records the splice that replaces the node with the value v.
+func (e *editor) replace(n *node, v any) error {
+	s, err := e.marshal(v, lineIndent(e.b, n.start))
+	if err != nil {
+		return err
+	}
+	e.splices = append(e.splices, splice{start: n.start, end: n.end, text: s})
+	return nil
+}
+
+// marshal encodes a value with the document's indentation, prefixing
+// the lines after the first with prefix.
+func (e *editor) marshal(v any, prefix string) (string, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if e.indent != "" {
+		enc.SetIndent(prefix, e.indent)
+	}
+	if err := enc.Encode(v); err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(buf.String(), "\n"), nil
+}
+
+// apply returns the document with the splices applied.
+func (e *editor) apply() []byte {
+	sort.SliceStable(e.splices, func(i, j int) bool {
+		a, b := e.splices[i], e.splices[j]
+		if a.start != b.start {
+			return a.start < b.start
+		}
+		return a.end < b.end
+	})
+
+	var (
+		out bytes.Buffer
+		cur int
+	)
+	for _, s := range e.splices {
+		out.Write(e.b[cur:s.start])
+		out.WriteString(s.text)
+		cur = s.end
+	}
+	out.Write(e.b[cur:])
+	return out.Bytes()
+}
+
+// indent returns the indentation of the first indented line in the
+// document, or "" if the document isn't indented.
+func indent(b []byte) string {
+	for _, l := range strings.Split(string(b), "\n") {
+		s := strings.TrimLeft(l, " \t")
+		if s != "" && s != l {
+			return l[:len(l)-len(s)]
+		}
+	}
+	return ""
+}
+
+// lineIndent returns the leading whitespace of the line at i.
+func lineIndent(b []byte, i int) string {
+	l := b[bytes.LastIndexByte(b[:i], '\n')+1 : i]
+	return string(l[:len(l)-len(bytes.TrimLeft(l, " \t"))])
+}
+
+// equal compares decoded values. Numbers of different types
+// with the same value are equal.
+func equal(a, b any) bool {
+	if x, ok := toFloat(a); ok {
+		y, ok := toFloat(b)
+		return ok && x == y
+	}
+	if x, ok := toMap(a); ok {
+		y, ok := toMap(b)
+		if !ok || len(x) != len(y) {
+			return false
+		}
+		for k, v := range x {
+			if w, ok := y[k]; !ok || !equal(v, w) {
+				return false
+			}
+		}
+		return true
+	}
+	if x, ok := toSlice(a); ok {
+		y, ok := toSlice(b)
+		if !ok || len(x) != len(y) {
+			return false
+		}
+		for i := range x {
+			if !equal(x[i], y[i]) {
+				return false
+			}
+		}
+		return true
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+func toFloat(v any) (float64, bool) {
+	r := reflect.ValueOf(v)
+	switch r.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(r.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(r.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return r.Float(), true
+	}
+	return 0, false
+}
+
+func toMap(v any) (map[string]any, bool) {
+	if mp, ok := v.(map[string]any); ok {
+		return mp, true
+	}
+	r := reflect.ValueOf(v)
+	if r.Kind() != reflect.Map {
+		return nil, false
+	}
+	out := make(map[string]any, r.Len())
+	for it := r.MapRange(); it.Next(); {
+		out[fmt.Sprintf("%v", it.Key().Interface())] = it.Value().Interface()
+	}
+	return out, true
+}
+
+func toSlice(v any) ([]any, bool) {
+	if sl, ok := v.([]any); ok {
+		return sl, true
+	}
+	r := reflect.ValueOf(v)
+	if r.Kind() != reflect.Slice && r.Kind() != reflect.Array {
+		return nil, false
+	}
+	out := make([]any, r.Len())
+	for i := range out {
+		out[i] = r.Index(i).Interface()
+	}
+	return out, true
+}
+
+func sortedKeys(mp map[string]any) []string {
+	out := make([]string, 0, len(mp))
+	for k := range mp {
+		out = append(out, k)
+	}
+	sort.Strings(out)
+	return out
+}
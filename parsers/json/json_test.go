@@ -144,3 +144,127 @@ func TestJSON_Marshal(t *testing.T) {
 		})
 	}
 }
+
+func TestJSON_Edit(t *testing.T) {
+	testCases := []struct {
+		name   string
+		input  string
+		edit   func(mp map[string]any)
+		output string
+		isErr  bool
+	}{
+		{
+			name:   "Unchanged",
+			input:  "{\n  \"b\": 1,\n  \"a\": [1, 2]\n}\n",
+			edit:   func(mp map[string]any) {},
+			output: "{\n  \"b\": 1,\n  \"a\": [1, 2]\n}\n",
+		},
+		{
+			name:  "Set",
+			input: "{\n  \"b\": 1,\n  \"a\": {\n    \"x\": \"y\"\n  }\n}\n",
+			edit: func(mp map[string]any) {
+				mp["b"] = 2
+				mp["a"].(map[string]any)["x"] = "<z>"
+				mp["a"].(map[string]any)["new"] = map[string]any{"k": true}
+			},
+			output: "{\n  \"b\": 2,\n  \"a\": {\n    \"x\": \"<z>\",\n    \"new\": {\n      \"k\": true\n    }\n  }\n}\n",
+		},
+		{
+			name:  "Delete",
+			input: `{"a": 1, "b": 2, "c": 3, "d": [1, 2, 3]}`,
+			edit: func(mp map[string]any) {
+				delete(mp, "a")
+				delete(mp, "c")
+				mp["d"] = []any{1}
+			},
+			output: `{"b": 2, "d": [1]}`,
+		},
+		{
+			name:  "Append",
+			input: `{"a": [1, 2], "b": {}}`,
+			edit: func(mp map[string]any) {
+				mp["a"] = []any{1, 2, 3}
+				mp["b"] = map[string]any{"c": 1}
+			},
+			output: `{"a": [1, 2, 3], "b": {"c":1}}`,
+		},
+		{
+			name:  "Invalid JSON",
+			input: `{"a": `,
+			edit:  func(mp map[string]any) {},
+			isErr: true,
+		},
+	}
+
+	j := Parser()
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mp := map[string]any{}
+			if !tc.isErr {
+				var err error
+				mp, err = j.Unmarshal([]byte(tc.input))
+				assert.Nil(t, err)
+			}
+			tc.edit(mp)
+
+			out, err := j.Edit([]byte(tc.input), mp)
+			if tc.isErr {
+				assert.NotNil(t, err)
+				return
+			}
+			assert.Nil(t, err)
+			assert.Equal(t, tc.output, string(out))
+
+			res, err := j.Unmarshal(out)
+			assert.Nil(t, err)
+			assert.True(t, equal(mp, res))
+		})
+	}
+}
+
+func TestJSON_MarshalAnnotated(t *testing.T) {
+	var (
+		mp = map[string]any{
+			"name":    "a<b",
+			"tags":    []any{"a", "b"},
+			"db":      map[string]any{"port": int64(5432), "host": "localhost"},
+			"servers": []any{map[string]any{"port": int64(80), "host": ""}},
+			"extra":   map[string]any{},
+			"empty":   []any{},
+			"z":       true,
+		}
+		keys = []string{
+			"name", "tags", "db.port", "db.host", "db",
+			"servers.0.port", "servers.0.host", "servers", "extra", "empty",
+		}
+	)
+
+	out, err := Parser().MarshalAnnotated(mp, keys, map[string]string{"name": "Name."}, ".")
+	assert.Nil(t, err)
+	assert.Equal(t, `{
+  "name": "a<b",
+  "tags": [
+    "a",
+    "b"
+  ],
+  "db": {
+    "port": 5432,
+    "host": "localhost"
+  },
+  "servers": [
+    {
+      "port": 80,
+      "host": ""
+    }
+  ],
+  "extra": {},
+  "empty": [],
+  "z": true
+}
+`, string(out))
+
+	res, err := Parser().Unmarshal(out)
+	assert.Nil(t, err)
+	assert.True(t, equal(mp, res))
+}
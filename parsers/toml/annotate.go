@@ -0,0 +1,87 @@
+package toml
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/knadh/koanf/maps"
+)
+
+// annotation holds the order and the comments of the keys of a config map.
+type annotation struct {
+	*maps.Annotation
+	delim string
+}
+
+// MarshalAnnotated marshals the config map like Marshal, writing the keys in
+// the order of the full key paths in keys with the comments above them.
+// The keys of a table are written before its sub-tables as TOML requires.
+func (p *TOML) MarshalAnnotated(o map[string]any, keys []string, comments map[string]string, delim string) ([]byte, error) {
+	var (
+		a   = &annotation{maps.NewAnnotation(keys, comments, delim), delim}
+		buf bytes.Buffer
+	)
+	if err := a.writeTable(&buf, o, nil, nil); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// writeTable writes the keys and the sub-tables of the table with the
+// header path hdr at the full key path, which includes the indexes
+// of arrays of tables.
+func (a *annotation) writeTable(buf *bytes.Buffer, mp map[string]any, hdr, path []string) error {
+	keys := a.Keys(mp, path)
+	for _, k := range keys {
+		if isTable(mp[k]) {
+			continue
+		}
+
+		p := append(path[:len(path):len(path)], k)
+		val, err := marshalValue(mp[k])
+		if err != nil {
+			return fmt.Errorf("error encoding %s: %w", strings.Join(p, a.delim), err)
+		}
+		buf.WriteString(a.Comment(p, ""))
+		buf.WriteString(joinKey([]string{k}) + " = " + val + "\n")
+	}
+
+	for _, k := range keys {
+		if !isTable(mp[k]) {
+			continue
+		}
+
+		var (
+			h = append(hdr[:len(hdr):len(hdr)], k)
+			p = append(path[:len(path):len(path)], k)
+		)
+		if buf.Len() > 0 {
+			buf.WriteString("\n")
+		}
+		buf.WriteString(a.Comment(p, ""))
+
+		if m, ok := toMap(mp[k]); ok {
+			buf.WriteString("[" + joinKey(h) + "]\n")
+			if err := a.writeTable(buf, m, h, p); err != nil {
+				return err
+			}
+			continue
+		}
+
+		sl, _ := toSlice(mp[k])
+		for i, e := range sl {
+			if i > 0 {
+				buf.WriteString("\n")
+			}
+			buf.WriteString("[[" + joinKey(h) + "]]\n")
+			m, _ := toMap(e)
+			if err := a.writeTable(buf, m, h, append(p, strconv.Itoa(i))); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
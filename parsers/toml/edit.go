@@ -0,0 +1,572 @@
+package toml
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/pelletier/go-toml/v2"
+	"github.com/pelletier/go-toml/v2/unstable"
+)
+
+// item is a key/value or a table header in a TOML document.
+type item struct {
+	path  []string
+	table bool
+
+	// Byte offsets of the item's lines (and the comment lines directly
+	// above it) and of the value of a key/value.
+	start, end       int
+	valStart, valEnd int
+
+	// The path of the array of tables the item is a part of, if any.
+	array string
+}
+
+// section is the root or a table with a header that new keys can be added to.
+type section struct {
+	pos    int
+	indent string
+}
+
+// splice replaces the bytes between start and end with text.
+type splice struct {
+	start, end int
+	text       string
+}
+
+// editor applies a config map to a TOML document.
+type editor struct {
+	b      []byte
+	orig   map[string]any
+	items  []item
+	arrays map[string][]string
+
+	sections map[string]section
+	defined  map[string]bool
+	splices  []splice
+	tail     []string
+}
+
+// Edit returns the TOML document b updated to represent the given config map.
+// Unlike Marshal, comments, key order and the formatting of the values that
+// didn't change are preserved. Keys that are not in the map are removed from
+// the document. New keys are added to the end of their tables and new tables
+// to the end of the document. Changed arrays of tables are rewritten at
+// the end of the document.
+func (p *TOML) Edit(b []byte, o map[string]any) ([]byte, error) {
+	if len(bytes.TrimSpace(b)) == 0 {
+		return p.Marshal(o)
+	}
+
+	e := &editor{
+		b:        b,
+		arrays:   make(map[string][]string),
+		sections: make(map[string]section),
+	}
+	if err := toml.Unmarshal(b, &e.orig); err != nil {
+		return nil, err
+	}
+	if err := e.parse(); err != nil {
+		return nil, err
+	}
+	if err := e.edit(o); err != nil {
+		return nil, err
+	}
+	return e.apply(), nil
+}
+
+// parse records the items in the document.
+func (e *editor) parse() error {
+	var (
+		p     = unstable.Parser{}
+		table []string
+		array []string
+		sec   string
+		last  int
+	)
+	p.Reset(e.b)
+
+	// The root section is before the first table.
+	e.sections[""] = section{}
+
+	for p.NextExpression() {
+		n := p.Expression()
+		if n.Kind != unstable.KeyValue && n.Kind != unstable.Table && n.Kind != unstable.ArrayTable {
+			continue
+		}
+
+		var (
+			it         item
+			keys       []string
+			start, end = -1, 0
+		)
+		for k := n.Key(); k.Next(); {
+			r := k.Node().Raw
+			if start < 0 {
+				start = int(r.Offset)
+			}
+			end = int(r.Offset + r.Length)
+			keys = append(keys, string(k.Node().Data))
+		}
+
+		if n.Kind == unstable.KeyValue {
+			it.path = append(append([]string{}, table...), keys...)
+			it.valStart = skipSep(e.b, end)
+			it.valEnd = int(n.Raw.Offset + n.Raw.Length)
+			end = it.valEnd
+		} else {
+			table = keys
+			it.path, it.table = keys, true
+			end += bytes.IndexByte(e.b[end:], ']') + 1
+
+			if array != nil && !hasPrefix(keys, array) {
+				array = nil
+			}
+			if n.Kind == unstable.ArrayTable && array == nil {
+				array = keys
+				e.arrays[joinPath(keys)] = keys
+			}
+		}
+
+		it.start = leadStart(e.b, lineStart(e.b, start), last)
+		it.end = lineEnd(e.b, end)
+
+		// Tables are removed with the blank lines above them.
+		for it.table && it.start > last {
+			s := lineStart(e.b, it.start-1)
+			if s < last || len(bytes.TrimSpace(e.b[s:it.start])) > 0 {
+				break
+			}
+			it.start = s
+		}
+		if array != nil {
+			it.array = joinPath(array)
+		}
+		last = it.end
+
+		e.items = append(e.items, it)
+
+		// Key/values are added to the end of their tables.
+		switch {
+		case it.array != "":
+		case it.table:
+			sec = joinPath(it.path)
+			e.sections[sec] = section{pos: it.end}
+		default:
+			e.sections[sec] = section{pos: it.end, indent: indentOf(e.b, it.start, it.valStart)}
+		}
+	}
+
+	return p.Error()
+}
+
+// edit records the splices that apply the config map to the document.
+func (e *editor) edit(o map[string]any) error {
+	// Arrays of tables that changed are removed and rewritten.
+	changed := make(map[string]bool)
+	for id, path := range e.arrays {
+		v, ok := lookup(o, path)
+		if !ok || !equal(v, e.lookupOrig(path)) {
+			changed[id] = true
+		}
+	}
+
+	// Only the items that are kept define their paths.
+	e.defined = make(map[string]bool)
+	for id, path := range e.arrays {
+		if !changed[id] {
+			for i := 1; i <= len(path); i++ {
+				e.defined[joinPath(path[:i])] = true
+			}
+		}
+	}
+
+	for _, it := range e.items {
+		if it.array != "" {
+			if changed[it.array] {
+				e.remove(it)
+			}
+			continue
+		}
+
+		v, ok := lookup(o, it.path)
+		if _, isMap := toMap(v); !ok || (it.table && !isMap) {
+			e.remove(it)
+			continue
+		}
+		for i := 1; i <= len(it.path); i++ {
+			e.defined[joinPath(it.path[:i])] = true
+		}
+
+		if !it.table && !equal(v, e.lookupOrig(it.path)) {
+			s, err := marshalValue(v)
+			if err != nil {
+				return fmt.Errorf("error encoding %s: %w", strings.Join(it.path, "."), err)
+			}
+			if e.b[it.valStart] == '"' {
+				s = requote(s)
+			}
+			e.splices = append(e.splices, splice{start: it.valStart, end: it.valEnd, text: s})
+		}
+	}
+
+	return e.add(o, nil)
+}
+
+// lookupOrig returns the value at the path in the document.
+func (e *editor) lookupOrig(path []string) any {
+	v, _ := lookup(e.orig, path)
+	return v
+}
+
+// add records the splices that add the keys in the map
+// that are not in the document.
+func (e *editor) add(mp map[string]any, path []string) error {
+	for _, k := range sortedKeys(mp) {
+		var (
+			v = mp[k]
+			p = append(append([]string{}, path...), k)
+		)
+
+		if e.defined[joinPath(p)] {
+			if m, ok := toMap(v); ok && e.hasTable(p) {
+				if err := e.add(m, p); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+
+		// Tables and arrays of tables are appended to the document.
+		if isTable(v) {
+			s, err := marshalTable(path, k, v)
+			if err != nil {
+				return err
+			}
+			e.tail = append(e.tail, s)
+			continue
+		}
+
+		// Other values are added to the nearest table.
+		s, err := marshalValue(v)
+		if err != nil {
+			return fmt.Errorf("error encoding %s: %w", strings.Join(p, "."), err)
+		}
+		for i := len(path); i >= 0; i-- {
+			sec, ok := e.sections[joinPath(path[:i])]
+			if !ok {
+				continue
+			}
+
+			text := sec.indent + joinKey(p[i:]) + " = " + s + "\n"
+			if sec.pos > 0 && e.b[sec.pos-1] != '\n' {
+				text = "\n" + text
+			}
+			e.splices = append(e.splices, splice{start: sec.pos, end: sec.pos, text: text})
+			break
+		}
+	}
+	return nil
+}
+
+// hasTable checks whether a defined path is a table and not a key/value.
+func (e *editor) hasTable(path []string) bool {
+	id := joinPath(path)
+	for _, it := range e.items {
+		if it.array == "" && joinPath(it.path) == id {
+			return it.table
+		}
+	}
+	return true
+}
+
+// remove records the splice that removes the item.
+func (e *editor) remove(it item) {
+	e.splices = append(e.splices, splice{start: it.start, end: it.end})
+}
+
+// apply returns the document with the splices applied.
+func (e *editor) apply() []byte {
+	sort.SliceStable(e.splices, func(i, j int) bool {
+		a, b := e.splices[i], e.splices[j]
+		if a.start != b.start {
+			return a.start < b.start
+		}
+		return a.end < b.end
+	})
+
+	var (
+		out bytes.Buffer
+		cur int
+	)
+	for _, s := range e.splices {
+		out.Write(e.b[cur:s.start])
+		out.WriteString(s.text)
+		cur = s.end
+	}
+	out.Write(e.b[cur:])
+
+	// Drop the blank lines left at the beginning by removed tables.
+	if !bytes.HasPrefix(e.b, []byte("\n")) {
+		b := bytes.TrimLeft(out.Bytes(), "\n")
+		out.Next(out.Len() - len(b))
+	}
+
+	for _, s := range e.tail {
+		if out.Len() > 0 && !bytes.HasSuffix(out.Bytes(), []byte("\n\n")) {
+			if !bytes.HasSuffix(out.Bytes(), []byte("\n")) {
+				out.WriteByte('\n')
+			}
+			out.WriteByte('\n')
+		}
+		out.WriteString(s)
+	}
+	return out.Bytes()
+}
+
+// marshalValue returns the inline TOML representation of a value.
+func marshalValue(v any) (string, error) {
+	var buf bytes.Buffer
+	enc := toml.NewEncoder(&buf)
+	enc.SetTablesInline(true)
+	if err := enc.Encode(map[string]any{"v": v}); err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(strings.TrimPrefix(buf.String(), "v = "), "\n"), nil
+}
+
+// marshalTable returns the TOML representation of the table (or array of
+// tables) at the given parent path. The headers of the parents are omitted.
+func marshalTable(path []string, key string, v any) (string, error) {
+	mp := map[string]any{key: v}
+	for i := len(path) - 1; i >= 0; i-- {
+		mp = map[string]any{path[i]: mp}
+	}
+
+	b, err := toml.Marshal(mp)
+	if err != nil {
+		return "", fmt.Errorf("error encoding %s: %w", strings.Join(append(path, key), "."), err)
+	}
+
+	s := string(b)
+	for range path {
+		_, s, _ = strings.Cut(s, "\n")
+	}
+	return s, nil
+}
+
+// requote converts a single-line literal string to a basic string
+// to retain the quoting style of a value.
+func requote(s string) string {
+	if len(s) < 2 || s[0] != '\'' || strings.HasPrefix(s, "'''") {
+		return s
+	}
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s[1:len(s)-1]) + `"`
+}
+
+// isTable checks whether a value is written as a table or an
+// array of tables.
+func isTable(v any) bool {
+	if _, ok := toMap(v); ok {
+		return true
+	}
+	sl, ok := toSlice(v)
+	if !ok || len(sl) == 0 {
+		return false
+	}
+	for _, e := range sl {
+		if _, ok := toMap(e); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// lookup returns the value at the path in a config map.
+func lookup(mp map[string]any, path []string) (any, bool) {
+	var cur any = mp
+	for _, k := range path {
+		m, ok := toMap(cur)
+		if !ok {
+			return nil, false
+		}
+		if cur, ok = m[k]; !ok {
+			return nil, false
+		}
+	}
+	return cur, true
+}
+
+// skipSep returns the offset of the value after the end of
+// the key of a key/value.
+func skipSep(b []byte, i int) int {
+	for i < len(b) && (b[i] == ' ' || b[i] == '\t' || b[i] == '=') {
+		i++
+	}
+	return i
+}
+
+// lineStart returns the offset of the beginning of the line at i.
+func lineStart(b []byte, i int) int {
+	return bytes.LastIndexByte(b[:i], '\n') + 1
+}
+
+// lineEnd returns the offset after the newline of the line at i, including
+// a trailing comment.
+func lineEnd(b []byte, i int) int {
+	n := bytes.IndexByte(b[i:], '\n')
+	if n < 0 {
+		return len(b)
+	}
+	return i + n + 1
+}
+
+// leadStart returns the offset of the comment lines directly above the
+// line at i, but not before min.
+func leadStart(b []byte, i, min int) int {
+	for i > min {
+		s := lineStart(b, i-1)
+		if s < min || !bytes.HasPrefix(bytes.TrimSpace(b[s:i]), []byte("#")) {
+			break
+		}
+		i = s
+	}
+	return i
+}
+
+// indentOf returns the leading whitespace of the key/value at i.
+func indentOf(b []byte, start, i int) string {
+	s := lineStart(b, i)
+	if s < start {
+		s = start
+	}
+	l := b[s:i]
+	return string(l[:len(l)-len(bytes.TrimLeft(l, " \t"))])
+}
+
+// joinKey joins key parts into a dotted TOML key, quoting the
+// parts that are not bare keys.
+func joinKey(parts []string) string {
+	out := make([]string, len(parts))
+	for i, p := range parts {
+		out[i] = p
+		if !isBare(p) {
+			out[i] = `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(p) + `"`
+		}
+	}
+	return strings.Join(out, ".")
+}
+
+func isBare(s string) bool {
+	for _, c := range s {
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '_' || c == '-') {
+			return false
+		}
+	}
+	return s != ""
+}
+
+func joinPath(path []string) string {
+	return strings.Join(path, "\x00")
+}
+
+func hasPrefix(path, prefix []string) bool {
+	if len(path) < len(prefix) {
+		return false
+	}
+	for i := range prefix {
+		if path[i] != prefix[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// equal compares decoded values. Numbers of different types
+// with the same value are equal.
+func equal(a, b any) bool {
+	if x, ok := toFloat(a); ok {
+		y, ok := toFloat(b)
+		return ok && x == y
+	}
+	if x, ok := toMap(a); ok {
+		y, ok := toMap(b)
+		if !ok || len(x) != len(y) {
+			return false
+		}
+		for k, v := range x {
+			if w, ok := y[k]; !ok || !equal(v, w) {
+				return false
+			}
+		}
+		return true
+	}
+	if x, ok := toSlice(a); ok {
+		y, ok := toSlice(b)
+		if !ok || len(x) != len(y) {
+			return false
+		}
+		for i := range x {
+			if !equal(x[i], y[i]) {
+				return false
+			}
+		}
+		return true
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+func toFloat(v any) (float64, bool) {
+	r := reflect.ValueOf(v)
+	switch r.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(r.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(r.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return r.Float(), true
+	}
+	return 0, false
+}
+
+func toMap(v any) (map[string]any, bool) {
+	if mp, ok := v.(map[string]any); ok {
+		return mp, true
+	}
+	r := reflect.ValueOf(v)
+	if r.Kind() != reflect.Map {
+		return nil, false
+	}
+	out := make(map[string]any, r.Len())
+	for it := r.MapRange(); it.Next(); {
+		out[fmt.Sprintf("%v", it.Key().Interface())] = it.Value().Interface()
+	}
+	return out, true
+}
+
+func toSlice(v any) ([]any, bool) {
+	if sl, ok := v.([]any); ok {
+		return sl, true
+	}
+	r := reflect.ValueOf(v)
+	if r.Kind() != reflect.Slice && r.Kind() != reflect.Array {
+		return nil, false
+	}
+	out := make([]any, r.Len())
+	for i := range out {
+		out[i] = r.Index(i).Interface()
+	}
+	return out, true
+}
+
+func sortedKeys(mp map[string]any) []string {
+	out := make([]string, 0, len(mp))
+	for k := range mp {
+		out = append(out, k)
+	}
+	sort.Strings(out)
+	return out
+}
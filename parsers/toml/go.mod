@@ -3,12 +3,15 @@ module github.com/knadh/koanf/parsers/toml/v2
 go 1.23.0
 
 require (
+	github.com/knadh/koanf/maps v0.2.0
 	github.com/pelletier/go-toml/v2 v2.3.1
 	github.com/stretchr/testify v1.9.0
 )
 
 require (
 	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/mitchellh/copystructure v1.2.0 // indirect
+	github.com/mitchellh/reflectwalk v1.0.2 // indirect
 	github.com/pmezard/go-difflib v1.0.0 // indirect
 	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
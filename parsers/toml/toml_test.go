@@ -137,3 +137,127 @@ c = 'd'
 		})
 	}
 }
+
+func TestTOML_Edit(t *testing.T) {
+	testCases := []struct {
+		name   string
+		input  string
+		edit   func(mp map[string]any)
+		output string
+	}{
+		{
+			name:   "Unchanged",
+			input:  "# Comment.\nb = 1 # One.\n\n[a]\nc = [1, 2]\n",
+			edit:   func(mp map[string]any) {},
+			output: "# Comment.\nb = 1 # One.\n\n[a]\nc = [1, 2]\n",
+		},
+		{
+			name:  "Set",
+			input: "title = \"app\"\n\n# Server.\n[server]\n  # Host.\n  host = \"localhost\" # Inline.\n  port = 80\n",
+			edit: func(mp map[string]any) {
+				mp["title"] = "new"
+				mp["debug"] = true
+				mp["server"].(map[string]any)["host"] = "example.com"
+				mp["server"].(map[string]any)["tags"] = []any{"a"}
+				mp["server"].(map[string]any)["tls"] = map[string]any{"enabled": true}
+			},
+			output: "title = \"new\"\ndebug = true\n\n# Server.\n[server]\n  # Host.\n  host = \"example.com\" # Inline.\n  port = 80\n  tags = ['a']\n\n[server.tls]\nenabled = true\n",
+		},
+		{
+			name:  "Delete",
+			input: "a = 1\n# B.\nb = 2\n\n[c]\nd = 1\n\n[e]\nf = 1\n",
+			edit: func(mp map[string]any) {
+				delete(mp, "b")
+				delete(mp, "c")
+			},
+			output: "a = 1\n\n[e]\nf = 1\n",
+		},
+		{
+			name:  "Arrays of tables",
+			input: "[[servers]]\nname = \"a\"\n\n[[servers]]\nname = \"b\"\n\n[db]\nhost = \"x\"\n",
+			edit: func(mp map[string]any) {
+				mp["servers"].([]any)[1].(map[string]any)["name"] = "c"
+			},
+			output: "[db]\nhost = \"x\"\n\n[[servers]]\nname = 'a'\n\n[[servers]]\nname = 'c'\n",
+		},
+	}
+
+	p := Parser()
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mp, err := p.Unmarshal([]byte(tc.input))
+			assert.Nil(t, err)
+			tc.edit(mp)
+
+			out, err := p.Edit([]byte(tc.input), mp)
+			assert.Nil(t, err)
+			assert.Equal(t, tc.output, string(out))
+
+			res, err := p.Unmarshal(out)
+			assert.Nil(t, err)
+			assert.True(t, equal(mp, res))
+		})
+	}
+
+	_, err := p.Edit([]byte("a = [1"), map[string]any{})
+	assert.NotNil(t, err)
+}
+
+func TestTOML_MarshalAnnotated(t *testing.T) {
+	var (
+		mp = map[string]any{
+			"name":    "app",
+			"timeout": "5s",
+			"tags":    []any{"a", "b"},
+			"db":      map[string]any{"port": int64(5432), "host": "localhost", "pool": map[string]any{"size": int64(2)}},
+			"servers": []any{map[string]any{"port": int64(80), "tls": map[string]any{"cert": ""}}},
+			"extra":   map[string]any{},
+			"z":       true,
+		}
+		keys = []string{
+			"name", "db.port", "db.host", "db.pool.size", "db", "timeout", "tags",
+			"servers.0.port", "servers.0.tls.cert", "servers", "extra",
+		}
+		comments = map[string]string{
+			"name":               "Name of the app.",
+			"db":                 "Database.\nUsed for storage.",
+			"db.host":            "Host.",
+			"servers":            "Servers.",
+			"servers.0.tls.cert": "Certificate file.",
+		}
+	)
+
+	out, err := Parser().MarshalAnnotated(mp, keys, comments, ".")
+	assert.Nil(t, err)
+	assert.Equal(t, `# Name of the app.
+name = 'app'
+timeout = '5s'
+tags = ['a', 'b']
+z = true
+
+# Database.
+# Used for storage.
+[db]
+port = 5432
+# Host.
+host = 'localhost'
+
+[db.pool]
+size = 2
+
+# Servers.
+[[servers]]
+port = 80
+
+[servers.tls]
+# Certificate file.
+cert = ''
+
+[extra]
+`, string(out))
+
+	res, err := Parser().Unmarshal(out)
+	assert.Nil(t, err)
+	assert.True(t, equal(mp, res))
+}
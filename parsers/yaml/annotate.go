@@ -0,0 +1,73 @@
+package yaml
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/knadh/koanf/maps"
+	"go.yaml.in/yaml/v3"
+)
+
+// annotation holds the order and the comments of the keys of a config map.
+type annotation struct {
+	*maps.Annotation
+	delim string
+}
+
+// MarshalAnnotated marshals the config map like Marshal, writing the keys in
+// the order of the full key paths in keys with the comments above them.
+func (p *YAML) MarshalAnnotated(o map[string]any, keys []string, comments map[string]string, delim string) ([]byte, error) {
+	a := &annotation{maps.NewAnnotation(keys, comments, delim), delim}
+	n, err := a.node(o, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(4)
+	if err := enc.Encode(n); err != nil {
+		return nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// node returns the YAML node of the value at the path.
+func (a *annotation) node(v any, path []string) (*yaml.Node, error) {
+	if mp, ok := toMap(v); ok {
+		n := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+		for _, k := range a.Keys(mp, path) {
+			p := append(path[:len(path):len(path)], k)
+			val, err := a.node(mp[k], p)
+			if err != nil {
+				return nil, err
+			}
+			key := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: k, HeadComment: strings.TrimSuffix(a.Comment(p, ""), "\n")}
+			n.Content = append(n.Content, key, val)
+		}
+		return n, nil
+	}
+
+	if sl, ok := toSlice(v); ok {
+		n := &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+		for i, e := range sl {
+			val, err := a.node(e, append(path[:len(path):len(path)], strconv.Itoa(i)))
+			if err != nil {
+				return nil, err
+			}
+			n.Content = append(n.Content, val)
+		}
+		return n, nil
+	}
+
+	var n yaml.Node
+	if err := n.Encode(v); err != nil {
+		return nil, fmt.Errorf("error encoding %s: %w", strings.Join(path, a.delim), err)
+	}
+	return &n, nil
+}
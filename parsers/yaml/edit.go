@@ -0,0 +1,277 @@
+package yaml
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"go.yaml.in/yaml/v3"
+)
+
+// Edit returns the YAML document b updated to represent the given config map.
+// Unlike Marshal, comments, key order and the styles of the values that
+// didn't change are preserved. Keys that are not in the map are removed from
+// the document and new keys are appended to their mappings. Blank lines are
+// not preserved and indentation is normalized to the document's indentation.
+func (p *YAML) Edit(b []byte, o map[string]any) ([]byte, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(b, &doc); err != nil {
+		return nil, err
+	}
+	if doc.Kind != yaml.DocumentNode || len(doc.Content) == 0 {
+		return p.Marshal(o)
+	}
+
+	// A second pass fixes aliases that were compared before the values
+	// of their anchors were changed.
+	for i := 0; i < 2; i++ {
+		if err := edit(doc.Content[0], o); err != nil {
+			return nil, err
+		}
+	}
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(indent(b))
+	if err := enc.Encode(&doc); err != nil {
+		return nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+
+	// The encoder omits the explicit document start marker.
+	out := buf.Bytes()
+	if bytes.HasPrefix(b, []byte("---")) && !bytes.HasPrefix(out, []byte("---")) {
+		out = append([]byte("---\n"), out...)
+	}
+	return out, nil
+}
+
+// edit updates the node in place to represent the value v.
+func edit(n *yaml.Node, v any) error {
+	var cur any
+	if err := n.Decode(&cur); err == nil && equal(cur, v) {
+		return nil
+	}
+
+	switch n.Kind {
+	case yaml.MappingNode:
+		if mp, ok := toMap(v); ok {
+			return editMap(n, mp)
+		}
+	case yaml.SequenceNode:
+		if sl, ok := toSlice(v); ok {
+			return editSeq(n, sl)
+		}
+	}
+
+	return replace(n, v)
+}
+
+// editMap updates the pairs of a mapping node to represent the map.
+func editMap(n *yaml.Node, mp map[string]any) error {
+	var (
+		keys   = make(map[string]bool, len(mp))
+		merged bool
+
+		// Pairs are removed in place.
+		out = n.Content[:0]
+	)
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		k, v := n.Content[i], n.Content[i+1]
+
+		// Merge keys are left as they are. The resolved tag is cleared
+		// so that it's not written out explicitly.
+		if k.ShortTag() == "!!merge" {
+			merged = true
+			k.Tag = ""
+			out = append(out, k, v)
+			continue
+		}
+
+		val, ok := mp[k.Value]
+		if !ok {
+			continue
+		}
+		if err := edit(v, val); err != nil {
+			return err
+		}
+		keys[k.Value] = true
+		out = append(out, k, v)
+	}
+	n.Content = out
+
+	// Keys that are inherited through merge keys with the same
+	// values are not added to the mapping.
+	var inherited map[string]any
+	if merged {
+		if err := n.Decode(&inherited); err != nil {
+			return err
+		}
+	}
+
+	for _, k := range sortedKeys(mp) {
+		if keys[k] {
+			continue
+		}
+		if v, ok := inherited[k]; ok && equal(v, mp[k]) {
+			continue
+		}
+
+		var val yaml.Node
+		if err := val.Encode(mp[k]); err != nil {
+			return fmt.Errorf("error encoding %s: %w", k, err)
+		}
+		key := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: k}
+		n.Content = append(n.Content, key, &val)
+	}
+
+	return nil
+}
+
+// editSeq updates the elements of a sequence node to represent the slice.
+func editSeq(n *yaml.Node, sl []any) error {
+	if len(sl) < len(n.Content) {
+		n.Content = n.Content[:len(sl)]
+	}
+	for i, v := range sl {
+		if i < len(n.Content) {
+			if err := edit(n.Content[i], v); err != nil {
+				return err
+			}
+			continue
+		}
+
+		var val yaml.Node
+		if err := val.Encode(v); err != nil {
+			return err
+		}
+		n.Content = append(n.Content, &val)
+	}
+	return nil
+}
+
+// replace replaces the node with the encoded value v, retaining its
+// comments, anchor, and the quoting style of strings.
+func replace(n *yaml.Node, v any) error {
+	var val yaml.Node
+	if err := val.Encode(v); err != nil {
+		return err
+	}
+
+	if n.Kind == yaml.ScalarNode && val.Kind == yaml.ScalarNode &&
+		n.ShortTag() == "!!str" && val.ShortTag() == "!!str" && val.Style == 0 &&
+		n.Style&(yaml.SingleQuotedStyle|yaml.DoubleQuotedStyle) != 0 &&
+		!strings.Contains(val.Value, "\n") {
+		val.Style = n.Style
+	}
+
+	val.Anchor = n.Anchor
+	val.HeadComment = n.HeadComment
+	val.LineComment = n.LineComment
+	val.FootComment = n.FootComment
+	*n = val
+	return nil
+}
+
+// indent returns the indentation of the first indented line in the
+// document, or 4, which is what Marshal uses.
+func indent(b []byte) int {
+	for _, l := range strings.Split(string(b), "\n") {
+		s := strings.TrimLeft(l, " ")
+		if s == "" || s == l || strings.HasPrefix(s, "#") {
+			continue
+		}
+		return len(l) - len(s)
+	}
+	return 4
+}
+
+// equal compares decoded values. Numbers of different types
+// with the same value are equal.
+func equal(a, b any) bool {
+	if x, ok := toFloat(a); ok {
+		y, ok := toFloat(b)
+		return ok && x == y
+	}
+	if x, ok := toMap(a); ok {
+		y, ok := toMap(b)
+		if !ok || len(x) != len(y) {
+			return false
+		}
+		for k, v := range x {
+			if w, ok := y[k]; !ok || !equal(v, w) {
+				return false
+			}
+		}
+		return true
+	}
+	if x, ok := toSlice(a); ok {
+		y, ok := toSlice(b)
+		if !ok || len(x) != len(y) {
+			return false
+		}
+		for i := range x {
+			if !equal(x[i], y[i]) {
+				return false
+			}
+		}
+		return true
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+func toFloat(v any) (float64, bool) {
+	r := reflect.ValueOf(v)
+	switch r.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(r.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(r.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return r.Float(), true
+	}
+	return 0, false
+}
+
+func toMap(v any) (map[string]any, bool) {
+	if mp, ok := v.(map[string]any); ok {
+		return mp, true
+	}
+	r := reflect.ValueOf(v)
+	if r.Kind() != reflect.Map {
+		return nil, false
+	}
+	out := make(map[string]any, r.Len())
+	for it := r.MapRange(); it.Next(); {
+		out[fmt.Sprintf("%v", it.Key().Interface())] = it.Value().Interface()
+	}
+	return out, true
+}
+
+func toSlice(v any) ([]any, bool) {
+	if sl, ok := v.([]any); ok {
+		return sl, true
+	}
+	r := reflect.ValueOf(v)
+	if r.Kind() != reflect.Slice && r.Kind() != reflect.Array {
+		return nil, false
+	}
+	out := make([]any, r.Len())
+	for i := range out {
+		out[i] = r.Index(i).Interface()
+	}
+	return out, true
+}
+
+func sortedKeys(mp map[string]any) []string {
+	out := make([]string, 0, len(mp))
+	for k := range mp {
+		out = append(out, k)
+	}
+	sort.Strings(out)
+	return out
+}
@@ -157,3 +157,117 @@ string: Hello World
 		})
 	}
 }
+
+func TestYAML_Edit(t *testing.T) {
+	testCases := []struct {
+		name   string
+		input  string
+		edit   func(mp map[string]any)
+		output string
+	}{
+		{
+			name:   "Unchanged",
+			input:  "# Comment.\nb: 1 # One.\na: [1, 2]\n",
+			edit:   func(mp map[string]any) {},
+			output: "# Comment.\nb: 1 # One.\na: [1, 2]\n",
+		},
+		{
+			name:  "Set",
+			input: "# Server.\nserver:\n  # Host.\n  host: \"localhost\" # Inline.\n  port: 80\n",
+			edit: func(mp map[string]any) {
+				mp["server"].(map[string]any)["host"] = "example.com"
+				mp["server"].(map[string]any)["tls"] = map[string]any{"enabled": true}
+				mp["new"] = []any{"a"}
+			},
+			output: "# Server.\nserver:\n  # Host.\n  host: \"example.com\" # Inline.\n  port: 80\n  tls:\n    enabled: true\nnew:\n  - a\n",
+		},
+		{
+			name:  "Delete",
+			input: "a: 1\n# B.\nb: 2\nc: [1, 2, 3]\n",
+			edit: func(mp map[string]any) {
+				delete(mp, "b")
+				mp["c"] = []any{1, 2, 4}
+			},
+			output: "a: 1\nc: [1, 2, 4]\n",
+		},
+		{
+			name:  "Anchors",
+			input: "base: &base\n  x: 1\nchild:\n  <<: *base\n  y: 2\n",
+			edit: func(mp map[string]any) {
+				mp["child"].(map[string]any)["y"] = 3
+			},
+			output: "base: &base\n  x: 1\nchild:\n  <<: *base\n  y: 3\n",
+		},
+	}
+
+	p := Parser()
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mp, err := p.Unmarshal([]byte(tc.input))
+			assert.Nil(t, err)
+			tc.edit(mp)
+
+			out, err := p.Edit([]byte(tc.input), mp)
+			assert.Nil(t, err)
+			assert.Equal(t, tc.output, string(out))
+
+			res, err := p.Unmarshal(out)
+			assert.Nil(t, err)
+			assert.True(t, equal(mp, res))
+		})
+	}
+
+	_, err := p.Edit([]byte("a: [1"), map[string]any{})
+	assert.NotNil(t, err)
+}
+
+func TestYAML_MarshalAnnotated(t *testing.T) {
+	var (
+		mp = map[string]any{
+			"name":    "app",
+			"timeout": "5s",
+			"tags":    []any{"a", "b"},
+			"db":      map[string]any{"port": int64(5432), "host": "localhost"},
+			"servers": []any{map[string]any{"port": int64(80), "host": ""}},
+			"extra":   map[string]any{},
+			"z":       true,
+		}
+		keys = []string{
+			"name", "timeout", "tags", "db.port", "db.host", "db",
+			"servers.0.port", "servers.0.host", "servers", "extra",
+		}
+		comments = map[string]string{
+			"name":           "Name of the app.",
+			"db":             "Database.\nUsed for storage.",
+			"db.host":        "Host.",
+			"servers.0.host": "Host to listen on.",
+		}
+	)
+
+	out, err := Parser().MarshalAnnotated(mp, keys, comments, ".")
+	assert.Nil(t, err)
+	assert.Equal(t, `# Name of the app.
+name: app
+timeout: 5s
+tags:
+    - a
+    - b
+# Database.
+# Used for storage.
+db:
+    port: 5432
+    # Host.
+    host: localhost
+servers:
+    - port: 80
+      # Host to listen on.
+      host: ""
+extra: {}
+z: true
+`, string(out))
+
+	res, err := Parser().Unmarshal(out)
+	assert.Nil(t, err)
+	assert.True(t, equal(mp, res))
+}
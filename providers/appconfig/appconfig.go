@@ -5,6 +5,7 @@ package appconfig
 import (
 	"context"
 	"errors"
+	"sync"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
@@ -13,8 +14,12 @@ import (
 	"github.com/aws/aws-sdk-go-v2/credentials/stscreds"
 	"github.com/aws/aws-sdk-go-v2/service/appconfig"
 	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/knadh/koanf/v2"
 )
 
+// Non-allocating compile-time check for interface implementation.
+var _ koanf.Watcher = (*AppConfig)(nil)
+
 // Config holds the AWS AppConfig Configuration.
 type Config struct {
 	// The AWS AppConfig Application to get. Specify either the application
@@ -63,6 +68,11 @@ type AppConfig struct {
 	client *appconfig.Client
 	config Config
 	input  appconfig.GetConfigurationInput
+
+	// Context and its cancel func of the active watch, if any.
+	mu       sync.Mutex
+	watchCtx context.Context
+	cancel   context.CancelFunc
 }
 
 // Provider returns an AWS AppConfig provider.
@@ -127,34 +137,75 @@ func (ac *AppConfig) Read() (map[string]any, error) {
 	return nil, errors.New("appconfig provider does not support this method")
 }
 
-// Watch polls AWS AppConfig for configuration updates.
-func (ac *AppConfig) Watch(cb func(event any, err error)) error {
+// Watch polls AWS AppConfig for configuration updates every WatchInterval
+// and triggers a callback when a new configuration version is available.
+// The polling goroutine exits when ctx is cancelled or Unwatch() is called.
+func (ac *AppConfig) Watch(ctx context.Context, cb func(ev koanf.Event, err error)) error {
 	if ac.config.WatchInterval == 0 {
 		// Set default watch interval to 60 seconds.
 		ac.config.WatchInterval = 60 * time.Second
 	}
 
+	ac.mu.Lock()
+	if ac.cancel != nil {
+		ac.mu.Unlock()
+		return errors.New("appconfig is already being watched")
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	ac.watchCtx, ac.cancel = ctx, cancel
+	ac.mu.Unlock()
+
 	go func() {
-	loop:
+		defer func() {
+			cancel()
+
+			// Clear the watch state unless a new watch has been started meanwhile.
+			ac.mu.Lock()
+			if ac.watchCtx == ctx {
+				ac.watchCtx, ac.cancel = nil, nil
+			}
+			ac.mu.Unlock()
+		}()
+
 		for {
-			conf, err := ac.client.GetConfiguration(context.TODO(), &ac.input)
+			conf, err := ac.client.GetConfiguration(ctx, &ac.input)
 			if err != nil {
-				cb(nil, err)
-				break loop
+				// Cancellation is not an error.
+				if ctx.Err() == nil {
+					cb(koanf.Event{}, err)
+				}
+				return
 			}
 
 			// Check if the configuration has been updated.
 			if len(conf.Content) == 0 {
 				// Configuration is not updated and we have the latest version.
 				// Sleep for WatchInterval and retry watcher.
-				time.Sleep(ac.config.WatchInterval)
+				select {
+				case <-ctx.Done():
+					return
+				case <-time.After(ac.config.WatchInterval):
+				}
 				continue
 			}
 
 			// Trigger event.
-			cb(nil, nil)
+			cb(koanf.Event{Key: ac.config.Configuration, Raw: conf}, nil)
 		}
 	}()
 
 	return nil
 }
+
+// Unwatch stops polling AWS AppConfig.
+func (ac *AppConfig) Unwatch() error {
+	ac.mu.Lock()
+	defer ac.mu.Unlock()
+
+	if ac.cancel != nil {
+		ac.cancel()
+		ac.watchCtx, ac.cancel = nil, nil
+	}
+
+	return nil
+}
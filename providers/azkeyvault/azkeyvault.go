@@ -77,3 +77,10 @@ func (kv *AzureKeyVault) Read() (map[string]any, error) {
 
 	return secrets, nil
 }
+
+// Sensitive returns true as Key Vault secrets are sensitive. It implements
+// koanf.SensitiveProvider so that the values loaded from Key Vault are
+// redacted in koanf's output helpers.
+func (kv *AzureKeyVault) Sensitive() bool {
+	return true
+}
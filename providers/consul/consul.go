@@ -1,11 +1,23 @@
 package consul
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"io/fs"
+	"strings"
+	"sync"
 
 	"github.com/hashicorp/consul/api"
 	"github.com/hashicorp/consul/api/watch"
+	"github.com/knadh/koanf/maps"
+	"github.com/knadh/koanf/v2"
+)
+
+// Non-allocating compile-time checks for interface implementations.
+var (
+	_ koanf.Watcher = (*Consul)(nil)
+	_ koanf.Writer  = (*Consul)(nil)
 )
 
 // Config represents the Consul client configuration.
@@ -26,6 +38,14 @@ type Config struct {
 	// This is analogous to: consul kv get -detailed key
 	Detailed bool
 
+	// Delimiter that Write() joins the keys of nested maps with, which
+	// should be the delimiter of the koanf instance. Defaults to ".".
+	Delim string
+
+	// If Prune is true, Write() deletes the keys under the prefix (Key)
+	// that are not in the config.
+	Prune bool
+
 	// Consul client config
 	Cfg *api.Config
 }
@@ -34,6 +54,10 @@ type Config struct {
 type Consul struct {
 	client *api.Client
 	cfg    Config
+
+	// Watch plan of the active watch, if any.
+	mu   sync.Mutex
+	plan *watch.Plan
 }
 
 // Provider returns an instance of the Consul provider.
@@ -51,7 +75,8 @@ func (c *Consul) ReadBytes() ([]byte, error) {
 	return nil, errors.New("consul provider does not support this method")
 }
 
-// Read reads configuration from the Consul provider.
+// Read reads configuration from the Consul provider. A Key that doesn't
+// exist returns an error that wraps fs.ErrNotExist, unless Recurse is set.
 func (c *Consul) Read() (map[string]any, error) {
 	var (
 		mp = make(map[string]any)
@@ -103,6 +128,9 @@ func (c *Consul) Read() (map[string]any, error) {
 	if err != nil {
 		return nil, err
 	}
+	if pair == nil {
+		return nil, fmt.Errorf("consul key %s: %w", c.cfg.Key, fs.ErrNotExist)
+	}
 
 	if c.cfg.Detailed {
 		m := make(map[string]any)
@@ -127,8 +155,82 @@ func (c *Consul) Read() (map[string]any, error) {
 	return mp, nil
 }
 
+// WriteBytes is not supported by the Consul provider.
+func (c *Consul) WriteBytes([]byte) error {
+	return errors.New("consul provider does not support this method")
+}
+
+// Write writes the config map to Consul. The keys of nested maps are joined
+// with Delim. String values are written as they are, and numbers and bools
+// as text, so that Read() returns the same values. Other values can't be
+// written. If Recurse is set, the keys under the prefix are written and if
+// Prune is set, the keys under the prefix that are not in the map are deleted.
+// Otherwise, only the key in the config is written. Other keys are ignored.
+// Writing is not supported with Detailed.
+func (c *Consul) Write(mp map[string]any) error {
+	if c.cfg.Detailed {
+		return errors.New("consul provider does not support writing detailed keys")
+	}
+
+	delim := c.cfg.Delim
+	if delim == "" {
+		delim = "."
+	}
+
+	pairs, err := maps.FlattenStrings(mp, delim)
+	if err != nil {
+		return fmt.Errorf("consul provider: %w", err)
+	}
+
+	kv := c.client.KV()
+	if !c.cfg.Recurse {
+		v, ok := pairs[c.cfg.Key]
+		if !ok {
+			if c.cfg.Prune {
+				_, err := kv.Delete(c.cfg.Key, nil)
+				return err
+			}
+			return nil
+		}
+		_, err := kv.Put(&api.KVPair{Key: c.cfg.Key, Value: []byte(v)}, nil)
+		return err
+	}
+
+	for k, v := range pairs {
+		if !strings.HasPrefix(k, c.cfg.Key) {
+			continue
+		}
+		if _, err := kv.Put(&api.KVPair{Key: k, Value: []byte(v)}, nil); err != nil {
+			return err
+		}
+	}
+
+	if !c.cfg.Prune {
+		return nil
+	}
+
+	keys, _, err := kv.Keys(c.cfg.Key, "", nil)
+	if err != nil {
+		return err
+	}
+	for _, k := range keys {
+		if _, ok := pairs[k]; ok {
+			continue
+		}
+		if _, err := kv.Delete(k, nil); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 // Watch watches for changes in the Consul API and triggers a callback.
-func (c *Consul) Watch(cb func(event any, err error)) error {
+// The event passed to the callback carries the watched key (or prefix) and
+// the value returned by the watch plan, which is *api.KVPair for a single key
+// and api.KVPairs for a prefix. The watch runs until ctx is cancelled or
+// Unwatch() is called.
+func (c *Consul) Watch(ctx context.Context, cb func(ev koanf.Event, err error)) error {
 	p := make(map[string]any)
 
 	if c.cfg.Recurse {
@@ -145,12 +247,55 @@ func (c *Consul) Watch(cb func(event any, err error)) error {
 	}
 
 	plan.Handler = func(_ uint64, val any) {
-		cb(val, nil)
+		cb(koanf.Event{Key: c.cfg.Key, Raw: val}, nil)
+	}
+
+	c.mu.Lock()
+	if c.plan != nil {
+		c.mu.Unlock()
+		return errors.New("consul key is already being watched")
 	}
+	c.plan = plan
+	c.mu.Unlock()
 
+	// Stop the plan when the context is cancelled.
+	done := make(chan struct{})
 	go func() {
-		plan.Run(c.cfg.Cfg.Address)
+		select {
+		case <-ctx.Done():
+			plan.Stop()
+		case <-done:
+		}
 	}()
 
+	go func() {
+		defer close(done)
+
+		err := plan.Run(c.cfg.Cfg.Address)
+
+		c.mu.Lock()
+		if c.plan == plan {
+			c.plan = nil
+		}
+		c.mu.Unlock()
+
+		if err != nil {
+			cb(koanf.Event{}, err)
+		}
+	}()
+
+	return nil
+}
+
+// Unwatch stops watching the key.
+func (c *Consul) Unwatch() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.plan != nil {
+		c.plan.Stop()
+		c.plan = nil
+	}
+
 	return nil
 }
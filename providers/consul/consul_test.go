@@ -0,0 +1,168 @@
+package consul
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/hashicorp/consul/api"
+	"github.com/knadh/koanf/v2"
+)
+
+// memKV serves a subset of the Consul KV HTTP API from memory.
+type memKV struct {
+	mu   sync.Mutex
+	data map[string]string
+}
+
+func (m *memKV) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	key := strings.TrimPrefix(r.URL.Path, "/v1/kv/")
+	switch r.Method {
+	case http.MethodPut:
+		b, _ := io.ReadAll(r.Body)
+		m.data[key] = string(b)
+		_, _ = w.Write([]byte("true"))
+
+	case http.MethodDelete:
+		delete(m.data, key)
+		_, _ = w.Write([]byte("true"))
+
+	case http.MethodGet:
+		var (
+			q          = r.URL.Query()
+			_, recurse = q["recurse"]
+			_, keys    = q["keys"]
+			pairs      api.KVPairs
+			names      []string
+		)
+		for k, v := range m.data {
+			if k == key || ((recurse || keys) && strings.HasPrefix(k, key)) {
+				pairs = append(pairs, &api.KVPair{Key: k, Value: []byte(v)})
+				names = append(names, k)
+			}
+		}
+		if len(pairs) == 0 {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		sort.Strings(names)
+		if keys {
+			_ = json.NewEncoder(w).Encode(names)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(pairs)
+	}
+}
+
+func newMem(t *testing.T, cfg Config, data map[string]string) (*Consul, *memKV) {
+	kv := &memKV{data: data}
+	srv := httptest.NewServer(kv)
+	t.Cleanup(srv.Close)
+
+	cfg.Cfg = &api.Config{Address: srv.URL}
+	c, err := Provider(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c, kv
+}
+
+func TestWrite(t *testing.T) {
+	p, kv := newMem(t, Config{Key: "app/", Recurse: true, Delim: "/"}, map[string]string{
+		"app/db/host": "localhost",
+		"app/db/port": "5432",
+		"app/old":     "x",
+		"other/key":   "y",
+	})
+
+	k := koanf.New("/")
+	if err := k.Load(p, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := k.Load(confmap(map[string]any{"from/env": "z"}), nil); err != nil {
+		t.Fatal(err)
+	}
+
+	// The flat keys read from Consul are set as they are, and new
+	// nested keys are joined with the delimiter.
+	if err := k.Set("app/db/host", "remote"); err != nil {
+		t.Fatal(err)
+	}
+	if err := k.Set("app/db/replicas", 2); err != nil {
+		t.Fatal(err)
+	}
+	k.Delete("app/old")
+	if err := k.Save(p, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	// Keys from other sources aren't written, and nothing is deleted by default.
+	exp := map[string]string{
+		"app/db/host":     "remote",
+		"app/db/port":     "5432",
+		"app/db/replicas": "2",
+		"app/old":         "x",
+		"other/key":       "y",
+	}
+	if !reflect.DeepEqual(exp, kv.data) {
+		t.Fatalf("expected %v, got %v", exp, kv.data)
+	}
+
+	// The written values are read back as they were.
+	n := koanf.New("/")
+	if err := n.Load(p, nil); err != nil {
+		t.Fatal(err)
+	}
+	if v := n.String("app/db/replicas"); v != "2" {
+		t.Fatalf("expected 2, got %v", v)
+	}
+
+	// Pruning deletes the keys under the prefix that aren't in the config.
+	p.cfg.Prune = true
+	if err := k.Save(p, nil); err != nil {
+		t.Fatal(err)
+	}
+	delete(exp, "app/old")
+	if !reflect.DeepEqual(exp, kv.data) {
+		t.Fatalf("expected %v, got %v", exp, kv.data)
+	}
+
+	// Values that can't be read back aren't written.
+	if err := p.Write(map[string]any{"app": map[string]any{"tags": []any{"a", "b"}}}); err == nil {
+		t.Fatal("expected error writing a slice")
+	}
+}
+
+func TestWriteKey(t *testing.T) {
+	p, kv := newMem(t, Config{Key: "color"}, map[string]string{"color": "blue", "size": "1"})
+
+	if err := p.Write(map[string]any{"color": "red", "size": 2}); err != nil {
+		t.Fatal(err)
+	}
+	exp := map[string]string{"color": "red", "size": "1"}
+	if !reflect.DeepEqual(exp, kv.data) {
+		t.Fatalf("expected %v, got %v", exp, kv.data)
+	}
+
+	// Detailed keys can't be written.
+	p.cfg.Detailed = true
+	if err := p.Write(map[string]any{"color": "red"}); err == nil {
+		t.Fatal("expected error writing detailed keys")
+	}
+}
+
+// confmap is a Provider of a config map.
+type confmap map[string]any
+
+func (c confmap) ReadBytes() ([]byte, error)    { return nil, nil }
+func (c confmap) Read() (map[string]any, error) { return c, nil }
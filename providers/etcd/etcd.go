@@ -3,11 +3,22 @@ package etcd
 import (
 	"context"
 	"errors"
+	"fmt"
+	"strings"
+	"sync"
 	"time"
 
+	"github.com/knadh/koanf/maps"
+	"github.com/knadh/koanf/v2"
 	clientv3 "go.etcd.io/etcd/client/v3"
 )
 
+// Non-allocating compile-time checks for interface implementations.
+var (
+	_ koanf.Watcher = (*Etcd)(nil)
+	_ koanf.Writer  = (*Etcd)(nil)
+)
+
 type Config struct {
 	// etcd endpoints
 	Endpoints []string
@@ -26,12 +37,24 @@ type Config struct {
 
 	// key, key with prefix, etc.
 	Key string
+
+	// delimiter that Write() joins the keys of nested maps with, which
+	// should be the delimiter of the koanf instance. Defaults to ".".
+	Delim string
+
+	// delete the keys under the prefix that are not in the config on Write()
+	Prune bool
 }
 
 // Etcd implements the etcd config provider.
 type Etcd struct {
 	client *clientv3.Client
 	cfg    Config
+
+	// Context and its cancel func of the active watch, if any.
+	mu       sync.Mutex
+	watchCtx context.Context
+	cancel   context.CancelFunc
 }
 
 // Provider returns a provider that takes etcd config.
@@ -93,22 +116,132 @@ func (e *Etcd) Read() (map[string]any, error) {
 	return mp, nil
 }
 
-func (e *Etcd) Watch(cb func(event any, err error)) error {
+// WriteBytes is not supported by etcd provider.
+func (e *Etcd) WriteBytes([]byte) error {
+	return errors.New("etcd provider does not support this method")
+}
+
+// Write writes the config map to etcd. The keys of nested maps are joined
+// with Delim. String values are written as they are, and numbers and bools
+// as text, so that Read() returns the same values. Other values can't be
+// written. If Prefix is set, the keys under the prefix are written and if
+// Prune is set, the keys under the prefix that are not in the map are deleted.
+// Otherwise, only the key in the config is written. Other keys are ignored.
+func (e *Etcd) Write(mp map[string]any) error {
+	delim := e.cfg.Delim
+	if delim == "" {
+		delim = "."
+	}
+
+	kv, err := maps.FlattenStrings(mp, delim)
+	if err != nil {
+		return fmt.Errorf("etcd provider: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), e.cfg.DialTimeout)
+	defer cancel()
+
+	if !e.cfg.Prefix {
+		v, ok := kv[e.cfg.Key]
+		if !ok {
+			if e.cfg.Prune {
+				_, err := e.client.Delete(ctx, e.cfg.Key)
+				return err
+			}
+			return nil
+		}
+		_, err := e.client.Put(ctx, e.cfg.Key, v)
+		return err
+	}
+
+	for k, v := range kv {
+		if !strings.HasPrefix(k, e.cfg.Key) {
+			continue
+		}
+		if _, err := e.client.Put(ctx, k, v); err != nil {
+			return err
+		}
+	}
+
+	if !e.cfg.Prune {
+		return nil
+	}
+
+	resp, err := e.client.Get(ctx, e.cfg.Key, clientv3.WithPrefix(), clientv3.WithKeysOnly())
+	if err != nil {
+		return err
+	}
+	for _, r := range resp.Kvs {
+		if _, ok := kv[string(r.Key)]; ok {
+			continue
+		}
+		if _, err := e.client.Delete(ctx, string(r.Key)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Watch watches the key (or the key prefix) for changes and triggers
+// a callback for every change event. The event passed to the callback carries
+// the changed key and the underlying *clientv3.Event. The watch runs until ctx
+// is cancelled or Unwatch() is called.
+func (e *Etcd) Watch(ctx context.Context, cb func(ev koanf.Event, err error)) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.cancel != nil {
+		return errors.New("etcd key is already being watched")
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	e.watchCtx, e.cancel = ctx, cancel
+
 	var w clientv3.WatchChan
+	if e.cfg.Prefix {
+		w = e.client.Watch(ctx, e.cfg.Key, clientv3.WithPrefix())
+	} else {
+		w = e.client.Watch(ctx, e.cfg.Key)
+	}
 
 	go func() {
-		if e.cfg.Prefix {
-			w = e.client.Watch(context.Background(), e.cfg.Key, clientv3.WithPrefix())
-		} else {
-			w = e.client.Watch(context.Background(), e.cfg.Key)
-		}
+		defer func() {
+			cancel()
 
+			// Clear the watch state unless a new watch has been started meanwhile.
+			e.mu.Lock()
+			if e.watchCtx == ctx {
+				e.watchCtx, e.cancel = nil, nil
+			}
+			e.mu.Unlock()
+		}()
+
+		// The watch channel is closed by the client when ctx is cancelled.
 		for wresp := range w {
+			if err := wresp.Err(); err != nil {
+				cb(koanf.Event{}, err)
+				return
+			}
+
 			for _, ev := range wresp.Events {
-				cb(ev, nil)
+				cb(koanf.Event{Key: string(ev.Kv.Key), Raw: ev}, nil)
 			}
 		}
 	}()
 
 	return nil
 }
+
+// Unwatch stops watching the key.
+func (e *Etcd) Unwatch() error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.cancel != nil {
+		e.cancel()
+		e.watchCtx, e.cancel = nil, nil
+	}
+
+	return nil
+}
@@ -0,0 +1,126 @@
+package etcd
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/knadh/koanf/v2"
+	"go.etcd.io/etcd/api/v3/mvccpb"
+	clientv3 "go.etcd.io/etcd/client/v3"
+)
+
+// memKV is an in-memory clientv3.KV that treats every Get as a prefix lookup.
+type memKV struct {
+	clientv3.KV
+	data map[string]string
+}
+
+func (m *memKV) Put(_ context.Context, key, val string, _ ...clientv3.OpOption) (*clientv3.PutResponse, error) {
+	m.data[key] = val
+	return &clientv3.PutResponse{}, nil
+}
+
+func (m *memKV) Get(_ context.Context, key string, _ ...clientv3.OpOption) (*clientv3.GetResponse, error) {
+	resp := &clientv3.GetResponse{}
+	for k, v := range m.data {
+		if strings.HasPrefix(k, key) {
+			resp.Kvs = append(resp.Kvs, &mvccpb.KeyValue{Key: []byte(k), Value: []byte(v)})
+		}
+	}
+	return resp, nil
+}
+
+func (m *memKV) Delete(_ context.Context, key string, _ ...clientv3.OpOption) (*clientv3.DeleteResponse, error) {
+	delete(m.data, key)
+	return &clientv3.DeleteResponse{}, nil
+}
+
+func newMem(cfg Config, data map[string]string) (*Etcd, *memKV) {
+	kv := &memKV{data: data}
+	return &Etcd{client: &clientv3.Client{KV: kv}, cfg: cfg}, kv
+}
+
+func TestWrite(t *testing.T) {
+	p, kv := newMem(Config{Key: "app/", Prefix: true, Delim: "/"}, map[string]string{
+		"app/db/host": "localhost",
+		"app/db/port": "5432",
+		"app/old":     "x",
+		"other/key":   "y",
+	})
+
+	k := koanf.New("/")
+	if err := k.Load(p, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := k.Load(confmap(map[string]any{"from/env": "z"}), nil); err != nil {
+		t.Fatal(err)
+	}
+	// The flat keys read from etcd are set as they are, and new
+	// nested keys are joined with the delimiter.
+	if err := k.Set("app/db/host", "remote"); err != nil {
+		t.Fatal(err)
+	}
+	if err := k.Set("app/db/replicas", 2); err != nil {
+		t.Fatal(err)
+	}
+	k.Delete("app/old")
+	if err := k.Save(p, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	// Keys from other sources aren't written, and nothing is deleted by default.
+	exp := map[string]string{
+		"app/db/host":     "remote",
+		"app/db/port":     "5432",
+		"app/db/replicas": "2",
+		"app/old":         "x",
+		"other/key":       "y",
+	}
+	if !reflect.DeepEqual(exp, kv.data) {
+		t.Fatalf("expected %v, got %v", exp, kv.data)
+	}
+
+	// The written values are read back as they were.
+	n := koanf.New("/")
+	if err := n.Load(p, nil); err != nil {
+		t.Fatal(err)
+	}
+	if v := n.String("app/db/replicas"); v != "2" {
+		t.Fatalf("expected 2, got %v", v)
+	}
+
+	// Pruning deletes the keys under the prefix that aren't in the config.
+	p.cfg.Prune = true
+	if err := k.Save(p, nil); err != nil {
+		t.Fatal(err)
+	}
+	delete(exp, "app/old")
+	if !reflect.DeepEqual(exp, kv.data) {
+		t.Fatalf("expected %v, got %v", exp, kv.data)
+	}
+
+	// Values that can't be read back aren't written.
+	if err := p.Write(map[string]any{"app": map[string]any{"tags": []any{"a", "b"}}}); err == nil {
+		t.Fatal("expected error writing a slice")
+	}
+}
+
+func TestWriteKey(t *testing.T) {
+	p, kv := newMem(Config{Key: "color"}, map[string]string{"color": "blue", "size": "1"})
+
+	if err := p.Write(map[string]any{"color": "red", "size": 2}); err != nil {
+		t.Fatal(err)
+	}
+	exp := map[string]string{"color": "red", "size": "1"}
+	if !reflect.DeepEqual(exp, kv.data) {
+		t.Fatalf("expected %v, got %v", exp, kv.data)
+	}
+}
+
+// confmap is a Provider of a config map.
+type confmap map[string]any
+
+func (c confmap) ReadBytes() ([]byte, error)    { return nil, nil }
+func (c confmap) Read() (map[string]any, error) { return c, nil }
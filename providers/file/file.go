@@ -4,6 +4,7 @@
 package file
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"os"
@@ -12,6 +13,13 @@ import (
 	"time"
 
 	"github.com/fsnotify/fsnotify"
+	"github.com/knadh/koanf/v2"
+)
+
+// Non-allocating compile-time checks for interface implementations.
+var (
+	_ koanf.Watcher = (*File)(nil)
+	_ koanf.Writer  = (*File)(nil)
 )
 
 // File implements a File provider.
@@ -39,9 +47,58 @@ func (f *File) Read() (map[string]any, error) {
 	return nil, errors.New("file provider does not support this method")
 }
 
-// Watch watches the file and triggers a callback when it changes. It is a
-// blocking function that internally spawns a goroutine to watch for changes.
-func (f *File) Watch(cb func(event any, err error)) error {
+// WriteBytes writes the bytes to the file atomically. The bytes are written
+// to a temporary file in the same directory, which then replaces the file,
+// so that readers never see a partially written file. The permissions of
+// an existing file are preserved. If the path is a symlink, its target
+// is replaced.
+func (f *File) WriteBytes(b []byte) error {
+	path := f.path
+	if p, err := filepath.EvalSymlinks(path); err == nil {
+		path = p
+	}
+
+	mode := os.FileMode(0o644)
+	if st, err := os.Stat(path); err == nil {
+		mode = st.Mode().Perm()
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+
+	// Remove the temporary file if it's not renamed.
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(b); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), mode); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
+
+// Write is not supported by the file provider.
+func (f *File) Write(map[string]any) error {
+	return errors.New("file provider does not support this method")
+}
+
+// Watch watches the file and triggers a callback when it changes. It
+// internally spawns a goroutine to watch for changes which exits when
+// ctx is cancelled or Unwatch() is called. The event passed to the callback
+// carries the file path and the underlying fsnotify.Event.
+func (f *File) Watch(ctx context.Context, cb func(ev koanf.Event, err error)) error {
 	f.mu.Lock()
 
 	// If a watcher already exists, return an error.
@@ -54,6 +111,7 @@ func (f *File) Watch(cb func(event any, err error)) error {
 	// can be detected.
 	realPath, err := filepath.EvalSymlinks(f.path)
 	if err != nil {
+		f.mu.Unlock()
 		return err
 	}
 	realPath = filepath.Clean(realPath)
@@ -62,24 +120,22 @@ func (f *File) Watch(cb func(event any, err error)) error {
 	// the whole parent directory to pick up all events such as symlink changes.
 	fDir, _ := filepath.Split(f.path)
 
-	f.w, err = fsnotify.NewWatcher()
+	w, err := fsnotify.NewWatcher()
 	if err != nil {
 		f.mu.Unlock()
 		return err
 	}
 
-	f.isWatching = true
-
 	// Set up the directory watch before releasing the lock
-	err = f.w.Add(fDir)
-	if err != nil {
-		f.w.Close()
-		f.w = nil
-		f.isWatching = false
+	if err := w.Add(fDir); err != nil {
+		w.Close()
 		f.mu.Unlock()
 		return err
 	}
 
+	f.w = w
+	f.isWatching = true
+
 	// Release the lock before spawning goroutine
 	f.mu.Unlock()
 
@@ -92,15 +148,18 @@ func (f *File) Watch(cb func(event any, err error)) error {
 	loop:
 		for {
 			select {
-			case event, ok := <-f.w.Events:
+			case <-ctx.Done():
+				break loop
+
+			case event, ok := <-w.Events:
 				if !ok {
 					// Only throw an error if we were still supposed to be watching.
 					f.mu.Lock()
-					stillWatching := f.isWatching
+					stillWatching := f.isWatching && f.w == w
 					f.mu.Unlock()
 
 					if stillWatching {
-						cb(nil, errors.New("fsnotify watch channel closed"))
+						cb(koanf.Event{}, errors.New("fsnotify watch channel closed"))
 					}
 
 					break loop
@@ -120,7 +179,7 @@ func (f *File) Watch(cb func(event any, err error)) error {
 				// target has changed.
 				curPath, err := filepath.EvalSymlinks(f.path)
 				if err != nil {
-					cb(nil, err)
+					cb(koanf.Event{}, err)
 					break loop
 				}
 				curPath = filepath.Clean(curPath)
@@ -138,37 +197,37 @@ func (f *File) Watch(cb func(event any, err error)) error {
 					realPath = curPath
 
 					// Trigger event.
-					cb(event, nil)
+					cb(koanf.Event{Key: f.path, Raw: event}, nil)
 				} else if onWatchedFile && event.Has(fsnotify.Remove) {
-					cb(nil, fmt.Errorf("file %s was removed", event.Name))
+					cb(koanf.Event{}, fmt.Errorf("file %s was removed", event.Name))
 					break loop
 				}
 
 			// There's an error.
-			case err, ok := <-f.w.Errors:
+			case err, ok := <-w.Errors:
 				if !ok {
 					// Only throw an error if we were still supposed to be watching.
 					f.mu.Lock()
-					stillWatching := f.isWatching
+					stillWatching := f.isWatching && f.w == w
 					f.mu.Unlock()
 
 					if stillWatching {
-						cb(nil, errors.New("fsnotify err channel closed"))
+						cb(koanf.Event{}, errors.New("fsnotify err channel closed"))
 					}
 
 					break loop
 				}
 
 				// Pass the error to the callback.
-				cb(nil, err)
+				cb(koanf.Event{}, err)
 				break loop
 			}
 		}
 
 		f.mu.Lock()
-		f.isWatching = false
-		if f.w != nil {
-			f.w.Close()
+		w.Close()
+		if f.w == w {
+			f.isWatching = false
 			f.w = nil
 		}
 		f.mu.Unlock()
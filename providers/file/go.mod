@@ -2,6 +2,15 @@ module github.com/knadh/koanf/providers/file
 
 go 1.23.0
 
-require github.com/fsnotify/fsnotify v1.9.0
+require (
+	github.com/fsnotify/fsnotify v1.9.0
+	github.com/knadh/koanf/v2 v2.4.0
+)
 
-require golang.org/x/sys v0.32.0 // indirect
+require (
+	github.com/go-viper/mapstructure/v2 v2.4.0 // indirect
+	github.com/knadh/koanf/maps v0.2.0 // indirect
+	github.com/mitchellh/copystructure v1.2.0 // indirect
+	github.com/mitchellh/reflectwalk v1.0.2 // indirect
+	golang.org/x/sys v0.32.0 // indirect
+)
@@ -13,6 +13,16 @@ import (
 	"io/fs"
 )
 
+// WriteFileFS is an fs.FS that can write files, for instance, an in-memory
+// or a remote file system. The FS provider can write only to such file systems.
+type WriteFileFS interface {
+	fs.FS
+
+	// WriteFile writes data to the named file, creating it with the
+	// permissions perm if necessary, like os.WriteFile.
+	WriteFile(name string, data []byte, perm fs.FileMode) error
+}
+
 // FS implements an fs.FS provider.
 type FS struct {
 	fs   fs.FS
@@ -39,3 +49,23 @@ func (f *FS) ReadBytes() ([]byte, error) {
 func (f *FS) Read() (map[string]any, error) {
 	return nil, errors.New("fs.FS provider does not support this method")
 }
+
+// WriteBytes writes the bytes to the file in the fs.FS, which has to implement
+// WriteFileFS. The permissions of an existing file are preserved.
+func (f *FS) WriteBytes(b []byte) error {
+	w, ok := f.fs.(WriteFileFS)
+	if !ok {
+		return errors.New("fs.FS does not support writing files")
+	}
+
+	mode := fs.FileMode(0o644)
+	if st, err := fs.Stat(f.fs, f.path); err == nil {
+		mode = st.Mode().Perm()
+	}
+	return w.WriteFile(f.path, b, mode)
+}
+
+// Write is not supported by the fs.FS provider.
+func (f *FS) Write(map[string]any) error {
+	return errors.New("fs.FS provider does not support this method")
+}
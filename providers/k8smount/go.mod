@@ -4,8 +4,8 @@ go 1.25
 
 require (
 	github.com/fsnotify/fsnotify v1.9.0
-	github.com/knadh/koanf/maps v0.1.2
-	github.com/knadh/koanf/v2 v2.3.4
+	github.com/knadh/koanf/maps v0.2.0
+	github.com/knadh/koanf/v2 v2.4.0
 	github.com/stretchr/testify v1.11.1
 )
 
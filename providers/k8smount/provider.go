@@ -20,6 +20,7 @@
 package k8smount
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"io/fs"
@@ -39,7 +40,10 @@ import (
 var ErrAlreadyWatched = errors.New("mount is already being watched")
 
 // Non-allocating compile-time check for interface implementation.
-var _ koanf.Provider = (*K8SMount)(nil)
+var (
+	_ koanf.Provider = (*K8SMount)(nil)
+	_ koanf.Watcher  = (*K8SMount)(nil)
+)
 
 // Opt represents optional configuration passed to the provider.
 type Opt struct {
@@ -182,8 +186,8 @@ func (k *K8SMount) walkDir(mountFS fs.FS, path string, d fs.DirEntry, err error)
 //
 // If an error occurs, the function is called with the error before the watch is stopped. If the
 // function is called with a nil error value, a change was detected successfully and watching will
-// continue.
-func (k *K8SMount) Watch(fn func(any, error)) error {
+// continue. The watch is stopped when ctx is cancelled or Unwatch is called.
+func (k *K8SMount) Watch(ctx context.Context, fn func(koanf.Event, error)) error {
 	if k.watching.Swap(true) {
 		return ErrAlreadyWatched
 	}
@@ -194,12 +198,12 @@ func (k *K8SMount) Watch(fn func(any, error)) error {
 	}
 
 	k.watcher = watcher
-	go k.watchDir(fn)
+	go k.watchDir(ctx, watcher, fn)
 
 	return watcher.Add(k.mount)
 }
 
-func (k *K8SMount) watchDir(fn func(any, error)) {
+func (k *K8SMount) watchDir(ctx context.Context, watcher *fsnotify.Watcher, fn func(koanf.Event, error)) {
 	defer k.watching.Store(false)
 
 	var (
@@ -209,7 +213,11 @@ func (k *K8SMount) watchDir(fn func(any, error)) {
 
 	for {
 		select {
-		case event, ok := <-k.watcher.Events:
+		case <-ctx.Done():
+			watcher.Close()
+			return
+
+		case event, ok := <-watcher.Events:
 			if !ok {
 				return
 			}
@@ -223,14 +231,14 @@ func (k *K8SMount) watchDir(fn func(any, error)) {
 			lastEvent = event.String()
 			lastEventTime = time.Now()
 
-			fn(nil, nil)
+			fn(koanf.Event{Key: event.Name, Raw: event}, nil)
 
-		case err, ok := <-k.watcher.Errors:
+		case err, ok := <-watcher.Errors:
 			if !ok {
 				return
 			}
 
-			fn(nil, err)
+			fn(koanf.Event{}, err)
 			return
 		}
 	}
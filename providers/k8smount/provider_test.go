@@ -1,6 +1,7 @@
 package k8smount_test
 
 import (
+	"context"
 	"os"
 	"path/filepath"
 	"strings"
@@ -9,6 +10,7 @@ import (
 	"time"
 
 	"github.com/knadh/koanf/providers/k8smount"
+	"github.com/knadh/koanf/v2"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -232,7 +234,7 @@ func Test_K8SMount_Watch_Success(t *testing.T) {
 	var watched atomic.Bool
 
 	// act
-	require.NoError(t, provider.Watch(func(_ any, err error) {
+	require.NoError(t, provider.Watch(context.Background(), func(_ koanf.Event, err error) {
 		assert.NoError(t, err)
 		watched.Store(true)
 	}))
@@ -259,7 +261,7 @@ func Test_K8SMount_Watch_AlreadyWatching(t *testing.T) {
 	dir := t.TempDir()
 	provider := k8smount.Provider(dir, "." /*delim*/, k8smount.Opt{})
 
-	require.NoError(t, provider.Watch(func(_ any, err error) {
+	require.NoError(t, provider.Watch(context.Background(), func(_ koanf.Event, err error) {
 		assert.NoError(t, err)
 	}))
 	defer func() {
@@ -267,7 +269,7 @@ func Test_K8SMount_Watch_AlreadyWatching(t *testing.T) {
 	}()
 
 	// act
-	err := provider.Watch(func(_ any, err error) {
+	err := provider.Watch(context.Background(), func(_ koanf.Event, err error) {
 		assert.NoError(t, err)
 	})
 
@@ -275,6 +277,27 @@ func Test_K8SMount_Watch_AlreadyWatching(t *testing.T) {
 	assert.ErrorIs(t, err, k8smount.ErrAlreadyWatched)
 }
 
+func Test_K8SMount_Watch_ContextCancel(t *testing.T) {
+	// arrange
+	dir := t.TempDir()
+	provider := k8smount.Provider(dir, "." /*delim*/, k8smount.Opt{})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	require.NoError(t, provider.Watch(ctx, func(_ koanf.Event, err error) {
+		assert.NoError(t, err)
+	}))
+
+	// act
+	cancel()
+
+	// assert
+	assert.Eventually(t, func() bool {
+		err := provider.Watch(context.Background(), func(_ koanf.Event, err error) {})
+		return err == nil
+	}, time.Second, 10*time.Millisecond)
+	assert.NoError(t, provider.Unwatch())
+}
+
 func Test_K8SMount_Unwatch(t *testing.T) {
 	// arrange
 	dir := t.TempDir()
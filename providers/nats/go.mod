@@ -3,8 +3,8 @@ module github.com/knadh/koanf/providers/nats
 go 1.25.0
 
 require (
-	github.com/knadh/koanf/maps v0.1.2
-	github.com/knadh/koanf/v2 v2.0.0
+	github.com/knadh/koanf/maps v0.2.0
+	github.com/knadh/koanf/v2 v2.4.0
 	github.com/nats-io/nats-server/v2 v2.11.12
 	github.com/nats-io/nats.go v1.52.0
 	github.com/stretchr/testify v1.8.4
@@ -13,13 +13,13 @@ require (
 require (
 	github.com/antithesishq/antithesis-sdk-go v0.5.0-default-no-op // indirect
 	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/go-viper/mapstructure/v2 v2.4.0 // indirect
 	github.com/google/go-tpm v0.9.8 // indirect
 	github.com/klauspost/compress v1.18.6 // indirect
 	github.com/kr/pretty v0.2.1 // indirect
 	github.com/kr/text v0.2.0 // indirect
 	github.com/minio/highwayhash v1.0.4-0.20251030100505-070ab1a87a76 // indirect
 	github.com/mitchellh/copystructure v1.2.0 // indirect
-	github.com/mitchellh/mapstructure v1.5.0 // indirect
 	github.com/mitchellh/reflectwalk v1.0.2 // indirect
 	github.com/nats-io/jwt/v2 v2.8.0 // indirect
 	github.com/nats-io/nkeys v0.4.15 // indirect
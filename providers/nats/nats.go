@@ -1,15 +1,25 @@
 package nats
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"io/fs"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/knadh/koanf/maps"
+	"github.com/knadh/koanf/v2"
 	"github.com/nats-io/nats.go"
 )
 
+// Non-allocating compile-time checks for interface implementations.
+var (
+	_ koanf.Watcher = (*Nats)(nil)
+	_ koanf.Writer  = (*Nats)(nil)
+)
+
 type Config struct {
 	// nats endpoint (comma separated urls are possible, eg "nats://one, nats://two").
 	URL string
@@ -30,12 +40,20 @@ type Config struct {
 	// So, "a.b.c" results in {"a": {"b": {"c": "value" }}}
 	// Prefix will be included
 	Unflatten bool
+
+	// If true, Write() deletes the keys under the prefix that are
+	// not in the config.
+	Prune bool
 }
 
 // Nats implements the nats config provider.
 type Nats struct {
 	kv  nats.KeyValue
 	cfg Config
+
+	// Key watcher of the active watch, if any.
+	mu sync.Mutex
+	w  nats.KeyWatcher
 }
 
 // Provider returns a provider that takes nats config.
@@ -63,9 +81,13 @@ func (n *Nats) ReadBytes() ([]byte, error) {
 	return nil, errors.New("nats provider does not support this method")
 }
 
-// Read returns a nested config map.
+// Read returns a nested config map. An empty bucket returns an error that
+// wraps fs.ErrNotExist.
 func (n *Nats) Read() (map[string]any, error) {
 	keys, err := n.kv.Keys()
+	if errors.Is(err, nats.ErrNoKeysFound) {
+		return nil, fmt.Errorf("%w: %w", fs.ErrNotExist, err)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -88,21 +110,112 @@ func (n *Nats) Read() (map[string]any, error) {
 	return mp, nil
 }
 
-func (n *Nats) Watch(cb func(event any, err error)) error {
+// WriteBytes is not supported by nats provider.
+func (n *Nats) WriteBytes([]byte) error {
+	return errors.New("nats provider does not support this method")
+}
+
+// Write writes the config map to the bucket. Nested maps are written to keys
+// joined with ".", like Read() unflattens them. String values are written as
+// they are, and numbers and bools as text, so that Read() returns the same
+// values. Other values can't be written. Only the keys under the prefix are
+// written and if Prune is set, the keys under the prefix that are not in the
+// map are deleted.
+func (n *Nats) Write(mp map[string]any) error {
+	kv, err := maps.FlattenStrings(mp, ".")
+	if err != nil {
+		return fmt.Errorf("nats provider: %w", err)
+	}
+
+	for k, v := range kv {
+		if !strings.HasPrefix(k, n.cfg.Prefix) {
+			continue
+		}
+		if _, err := n.kv.PutString(k, v); err != nil {
+			return err
+		}
+	}
+
+	if !n.cfg.Prune {
+		return nil
+	}
+
+	existing, err := n.kv.Keys()
+	if err != nil && !errors.Is(err, nats.ErrNoKeysFound) {
+		return err
+	}
+	for _, k := range existing {
+		if _, ok := kv[k]; ok || !strings.HasPrefix(k, n.cfg.Prefix) {
+			continue
+		}
+		if err := n.kv.Delete(k); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Watch watches the keys under the prefix and triggers a callback for
+// every new update. The event passed to the callback carries the updated key
+// and the underlying nats.KeyValueEntry. The watch runs until ctx is cancelled
+// or Unwatch() is called.
+func (n *Nats) Watch(ctx context.Context, cb func(ev koanf.Event, err error)) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if n.w != nil {
+		return errors.New("nats bucket is already being watched")
+	}
+
 	w, err := n.kv.Watch(fmt.Sprintf("%s.*", n.cfg.Prefix))
 	if err != nil {
 		return err
 	}
+	n.w = w
 
 	start := time.Now()
-	go func(watcher nats.KeyWatcher) {
-		for update := range watcher.Updates() {
-			// ignore nil events and only callback when the event is new (nats always sends one "old" event)
-			if update != nil && update.Created().After(start) {
-				cb(update, nil)
+	go func() {
+		defer func() {
+			n.mu.Lock()
+			if n.w == w {
+				n.w = nil
+			}
+			n.mu.Unlock()
+			w.Stop()
+		}()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+
+			case update, ok := <-w.Updates():
+				if !ok {
+					return
+				}
+
+				// ignore nil events and only callback when the event is new (nats always sends one "old" event)
+				if update != nil && update.Created().After(start) {
+					cb(koanf.Event{Key: update.Key(), Raw: update}, nil)
+				}
 			}
 		}
-	}(w)
+	}()
 
 	return nil
 }
+
+// Unwatch stops watching the bucket.
+func (n *Nats) Unwatch() error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if n.w == nil {
+		return nil
+	}
+
+	w := n.w
+	n.w = nil
+	return w.Stop()
+}
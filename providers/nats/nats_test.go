@@ -4,6 +4,7 @@
 package nats
 
 import (
+	"context"
 	"testing"
 	"time"
 
@@ -49,7 +50,7 @@ func TestNats(t *testing.T) {
 	assert.Equal(t, k.Keys(), []string{"some.test.color"})
 	assert.Equal(t, k.Get("some.test.color"), "blue")
 
-	err = provider.Watch(func(event any, err error) {
+	err = provider.Watch(context.Background(), func(_ koanf.Event, err error) {
 		if err != nil {
 			t.Fatal(err)
 		}
@@ -74,4 +75,77 @@ func TestNats(t *testing.T) {
 	time.Sleep(100 * time.Millisecond)
 
 	assert.Equal(t, k.Get("some.test.color"), "yellow")
+	assert.NoError(t, provider.Unwatch())
+}
+
+func TestNatsWrite(t *testing.T) {
+	nc, err := nats.Connect(testNatsURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer nc.Drain()
+
+	js, err := nc.JetStream()
+	if err != nil {
+		t.Fatal(err)
+	}
+	kv, err := js.CreateKeyValue(&nats.KeyValueConfig{
+		Bucket: "write",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for k, v := range map[string]string{"app.color": "blue", "app.old": "x", "other.key": "y"} {
+		if _, err := kv.PutString(k, v); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	provider, err := Provider(Config{
+		URL:       testNatsURL,
+		Bucket:    "write",
+		Prefix:    "app",
+		Unflatten: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	k := koanf.New(".")
+	if err := k.Load(provider, nil); err != nil {
+		t.Fatal(err)
+	}
+	assert.NoError(t, k.Set("app.env", "prod"))
+	assert.NoError(t, k.Set("app.color", "red"))
+	assert.NoError(t, k.Set("app.size.max", 10))
+	k.Delete("app.old")
+	k.Delete("app.env")
+	assert.NoError(t, k.Save(provider, nil))
+
+	// Nothing is deleted by default.
+	n := koanf.New(".")
+	if err := n.Load(provider, nil); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, map[string]any{"app.color": "red", "app.old": "x", "app.size.max": "10"}, n.All())
+
+	// Pruning deletes the keys under the prefix that aren't in the config.
+	provider.cfg.Prune = true
+	assert.NoError(t, k.Save(provider, nil))
+
+	n = koanf.New(".")
+	if err := n.Load(provider, nil); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, map[string]any{"app.color": "red", "app.size.max": "10"}, n.All())
+
+	// Values that can't be read back aren't written.
+	assert.Error(t, provider.Write(map[string]any{"app": map[string]any{"tags": []any{"a", "b"}}}))
+
+	// Keys outside the prefix are left as they are.
+	e, err := kv.Get("other.key")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "y", string(e.Value()))
 }
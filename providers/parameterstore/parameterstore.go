@@ -143,3 +143,10 @@ func (ps *ParameterStore[T]) Read() (map[string]any, error) {
 	}
 	return mp, nil
 }
+
+// Sensitive returns true as parameters may be secrets (SecureString).
+// It implements koanf.SensitiveProvider so that the values loaded from
+// Parameter Store are redacted in koanf's output helpers.
+func (ps *ParameterStore[T]) Sensitive() bool {
+	return true
+}
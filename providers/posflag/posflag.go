@@ -138,6 +138,9 @@ func (p *Posflag) ReadBytes() ([]byte, error) {
 }
 
 // Watch is not supported.
+//
+// Deprecated: flags don't change and the provider doesn't implement
+// koanf.Watcher. This method always returns an error.
 func (p *Posflag) Watch(cb func(event any, err error)) error {
 	return errors.New("posflag provider does not support this method")
 }
@@ -103,3 +103,10 @@ func (r *Vault) Read() (map[string]any, error) {
 
 	return s, nil
 }
+
+// Sensitive returns true as Vault secrets are sensitive. It implements
+// koanf.SensitiveProvider so that the values loaded from Vault are
+// redacted in koanf's output helpers.
+func (r *Vault) Sensitive() bool {
+	return true
+}
@@ -0,0 +1,80 @@
+// Package awssm implements a koanf.Resolver that resolves awssm:// secret
+// references, for instance, `awssm://prod/db#password`, to secrets in
+// AWS Secrets Manager.
+package awssm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+)
+
+// AWSSM implements an AWS Secrets Manager secret reference resolver.
+type AWSSM struct {
+	client *secretsmanager.Client
+}
+
+// Resolver returns an AWS Secrets Manager resolver.
+// The AWS Secrets Manager Client is configured via environment variables.
+// The configuration values are read from the environment variables.
+//   - AWS_REGION
+//   - AWS_ACCESS_KEY_ID
+//   - AWS_SECRET_ACCESS_KEY
+//   - AWS_SESSION_TOKEN
+func Resolver() (*AWSSM, error) {
+	c, err := awsconfig.LoadDefaultConfig(context.TODO())
+	if err != nil {
+		return nil, err
+	}
+	return ResolverWithClient(secretsmanager.NewFromConfig(c)), nil
+}
+
+// ResolverWithClient returns an AWS Secrets Manager resolver
+// using an existing AWS Secrets Manager client.
+func ResolverWithClient(client *secretsmanager.Client) *AWSSM {
+	return &AWSSM{client: client}
+}
+
+// Resolve fetches the current version of the secret in an awssm://secret-id#field
+// reference, where secret-id is the name or the ARN of the secret. If there's
+// a field, the secret string is parsed as a JSON object (as created by the
+// AWS console for key/value secrets) and the value of the field is returned.
+// Otherwise, the secret string, or the secret binary as []byte, is returned.
+func (a *AWSSM) Resolve(ref string) (any, error) {
+	id, ok := strings.CutPrefix(ref, "awssm://")
+	if !ok || id == "" {
+		return nil, fmt.Errorf("invalid awssm reference: %s", ref)
+	}
+	id, field, _ := strings.Cut(id, "#")
+
+	out, err := a.client.GetSecretValue(context.TODO(), &secretsmanager.GetSecretValueInput{SecretId: &id})
+	if err != nil {
+		return nil, err
+	}
+
+	if field == "" {
+		if out.SecretString != nil {
+			return *out.SecretString, nil
+		}
+		return out.SecretBinary, nil
+	}
+
+	if out.SecretString == nil {
+		return nil, fmt.Errorf("secret %s is not a JSON object", id)
+	}
+
+	var mp map[string]any
+	if err := json.Unmarshal([]byte(*out.SecretString), &mp); err != nil {
+		return nil, fmt.Errorf("secret %s is not a JSON object: %w", id, err)
+	}
+
+	val, ok := mp[field]
+	if !ok {
+		return nil, fmt.Errorf("field %s not found in secret %s", field, id)
+	}
+	return val, nil
+}
@@ -0,0 +1,95 @@
+package awssm
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsmiddleware "github.com/aws/aws-sdk-go-v2/aws/middleware"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+	"github.com/aws/smithy-go/middleware"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolve(t *testing.T) {
+	secrets := map[string]*secretsmanager.GetSecretValueOutput{
+		"prod/db":  {SecretString: aws.String(`{"user": "app", "password": "s3cret"}`)},
+		"prod/key": {SecretString: aws.String("abc")},
+		"prod/bin": {SecretBinary: []byte{1, 2}},
+	}
+
+	c, err := config.LoadDefaultConfig(
+		context.TODO(),
+		config.WithRegion("us-east-1"),
+		config.WithAPIOptions([]func(*middleware.Stack) error{
+			// Mock the SDK response using the middleware.
+			func(stack *middleware.Stack) error {
+				type key struct{}
+				err := stack.Initialize.Add(
+					middleware.InitializeMiddlewareFunc(
+						"MockInitialize",
+						func(ctx context.Context, in middleware.InitializeInput, next middleware.InitializeHandler) (out middleware.InitializeOutput, metadata middleware.Metadata, err error) {
+							if v, ok := in.Parameters.(*secretsmanager.GetSecretValueInput); ok {
+								ctx = middleware.WithStackValue(ctx, key{}, *v.SecretId)
+							}
+							return next.HandleInitialize(ctx, in)
+						},
+					), middleware.Before,
+				)
+				if err != nil {
+					return err
+				}
+				return stack.Finalize.Add(
+					middleware.FinalizeMiddlewareFunc(
+						"MockFinalize",
+						func(ctx context.Context, in middleware.FinalizeInput, next middleware.FinalizeHandler) (middleware.FinalizeOutput, middleware.Metadata, error) {
+							if awsmiddleware.GetOperationName(ctx) != "GetSecretValue" {
+								return middleware.FinalizeOutput{}, middleware.Metadata{}, nil
+							}
+
+							out, ok := secrets[middleware.GetStackValue(ctx, key{}).(string)]
+							if !ok {
+								return middleware.FinalizeOutput{}, middleware.Metadata{}, errors.New("secret not found")
+							}
+							return middleware.FinalizeOutput{Result: out}, middleware.Metadata{}, nil
+						},
+					),
+					middleware.Before,
+				)
+			},
+		}),
+	)
+	require.NoError(t, err)
+	r := ResolverWithClient(secretsmanager.NewFromConfig(c))
+
+	v, err := r.Resolve("awssm://prod/db#password")
+	require.NoError(t, err)
+	assert.Equal(t, "s3cret", v)
+
+	v, err = r.Resolve("awssm://prod/db")
+	require.NoError(t, err)
+	assert.Equal(t, `{"user": "app", "password": "s3cret"}`, v)
+
+	v, err = r.Resolve("awssm://prod/key")
+	require.NoError(t, err)
+	assert.Equal(t, "abc", v)
+
+	v, err = r.Resolve("awssm://prod/bin")
+	require.NoError(t, err)
+	assert.Equal(t, []byte{1, 2}, v)
+
+	_, err = r.Resolve("awssm://prod/db#nope")
+	assert.ErrorContains(t, err, "field nope not found")
+
+	_, err = r.Resolve("awssm://prod/key#field")
+	assert.Error(t, err)
+
+	_, err = r.Resolve("awssm://prod/missing")
+	assert.Error(t, err)
+
+	_, err = r.Resolve("vault://prod/db")
+	assert.Error(t, err)
+}
@@ -0,0 +1,49 @@
+// Package file implements a koanf.Resolver that resolves file:// secret
+// references, for instance, `file:///run/secrets/db_password`, to the
+// contents of the files. This is useful for secrets mounted as files by
+// Docker or Kubernetes.
+package file
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// File implements a file:// secret reference resolver.
+type File struct {
+	root string
+}
+
+// Resolver returns a file resolver. If root is not empty, references are
+// resolved relative to it and references that point outside of it are rejected.
+func Resolver(root string) *File {
+	if root != "" {
+		root = filepath.Clean(root)
+	}
+	return &File{root: root}
+}
+
+// Resolve returns the contents of the file in a file:///path/to/file
+// reference as a string with the trailing newlines trimmed.
+func (f *File) Resolve(ref string) (any, error) {
+	path, ok := strings.CutPrefix(ref, "file://")
+	if !ok || path == "" {
+		return nil, fmt.Errorf("invalid file reference: %s", ref)
+	}
+
+	path = filepath.Clean(path)
+	if f.root != "" {
+		path = filepath.Join(f.root, path)
+		if path != f.root && !strings.HasPrefix(path, f.root+string(filepath.Separator)) {
+			return nil, fmt.Errorf("file reference %s is outside %s", ref, f.root)
+		}
+	}
+
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return strings.TrimRight(string(b), "\r\n"), nil
+}
@@ -0,0 +1,34 @@
+package file
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolve(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "db"), []byte("s3cret\n"), 0600))
+
+	v, err := Resolver("").Resolve("file://" + filepath.Join(dir, "db"))
+	require.NoError(t, err)
+	assert.Equal(t, "s3cret", v)
+
+	// Relative to a root.
+	r := Resolver(dir)
+	v, err = r.Resolve("file:///db")
+	require.NoError(t, err)
+	assert.Equal(t, "s3cret", v)
+
+	_, err = r.Resolve("file:///../etc/passwd")
+	assert.Error(t, err)
+
+	_, err = r.Resolve("file:///missing")
+	assert.Error(t, err)
+
+	_, err = r.Resolve("vault://db")
+	assert.Error(t, err)
+}
@@ -0,0 +1,102 @@
+// Package vault implements a koanf.Resolver that resolves vault:// secret
+// references, for instance, `vault://secret/data/db#password`, to secrets
+// in Hashicorp Vault.
+package vault
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/vault/api"
+)
+
+// Config represents the Vault resolver configuration.
+type Config struct {
+	// Vault server address
+	Address string
+
+	// AuthMethod the Vault auth method https://developer.hashicorp.com/vault/docs/auth
+	AuthMethod api.AuthMethod
+
+	// Vault static token
+	Token string
+
+	// Internal HTTP client timeout
+	Timeout time.Duration
+
+	// Transport the optional HTTP client transport allows you to
+	// customize the settings like InsecureSkipVerify
+	Transport *http.Transport
+}
+
+// Vault implements a Vault secret reference resolver.
+type Vault struct {
+	client *api.Client
+}
+
+// Resolver returns a Vault resolver that takes a Vault config.
+func Resolver(cfg Config) (*Vault, error) {
+	httpClient := &http.Client{Timeout: cfg.Timeout}
+	if cfg.Transport != nil {
+		httpClient.Transport = cfg.Transport
+	}
+	client, err := api.NewClient(&api.Config{Address: cfg.Address, HttpClient: httpClient})
+	if err != nil {
+		return nil, err
+	}
+	if cfg.AuthMethod != nil {
+		if _, err := client.Auth().Login(context.Background(), cfg.AuthMethod); err != nil {
+			return nil, err
+		}
+	} else {
+		client.SetToken(cfg.Token)
+	}
+
+	return ResolverWithClient(client), nil
+}
+
+// ResolverWithClient returns a Vault resolver using an existing Vault client.
+func ResolverWithClient(client *api.Client) *Vault {
+	return &Vault{client: client}
+}
+
+// Resolve reads the secret at the path in a vault://path#field reference
+// and returns the value of the field. If there's no field, the whole secret
+// is returned as a map. With the KV v2 secrets engine, the path includes
+// the data/ prefix, for instance, `vault://secret/data/db#password`.
+func (v *Vault) Resolve(ref string) (any, error) {
+	path, ok := strings.CutPrefix(ref, "vault://")
+	if !ok || path == "" {
+		return nil, fmt.Errorf("invalid vault reference: %s", ref)
+	}
+	path, field, _ := strings.Cut(path, "#")
+
+	secret, err := v.client.Logical().Read(path)
+	if err != nil {
+		return nil, err
+	}
+	if secret == nil || secret.Data == nil {
+		return nil, fmt.Errorf("vault secret %s not found", path)
+	}
+
+	// KV v2 nests the secret's data under data along with its metadata.
+	data := secret.Data
+	if d, ok := data["data"].(map[string]any); ok {
+		if _, ok := data["metadata"]; ok {
+			data = d
+		}
+	}
+
+	if field == "" {
+		return data, nil
+	}
+
+	val, ok := data[field]
+	if !ok {
+		return nil, fmt.Errorf("field %s not found in vault secret %s", field, path)
+	}
+	return val, nil
+}
@@ -0,0 +1,54 @@
+package vault
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolve(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "test-token", r.Header.Get("X-Vault-Token"))
+
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/v1/secret/data/db":
+			w.Write([]byte(`{"data": {"data": {"user": "app", "password": "s3cret"}, "metadata": {"version": 1}}}`))
+		case "/v1/kv/api":
+			w.Write([]byte(`{"data": {"key": "abc"}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"errors": []}`))
+		}
+	}))
+	defer srv.Close()
+
+	v, err := Resolver(Config{Address: srv.URL, Token: "test-token"})
+	require.NoError(t, err)
+
+	// KV v2.
+	val, err := v.Resolve("vault://secret/data/db#password")
+	require.NoError(t, err)
+	assert.Equal(t, "s3cret", val)
+
+	val, err = v.Resolve("vault://secret/data/db")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"user": "app", "password": "s3cret"}, val)
+
+	// KV v1.
+	val, err = v.Resolve("vault://kv/api#key")
+	require.NoError(t, err)
+	assert.Equal(t, "abc", val)
+
+	_, err = v.Resolve("vault://secret/data/db#nope")
+	assert.ErrorContains(t, err, "field nope not found")
+
+	_, err = v.Resolve("vault://secret/data/missing#x")
+	assert.Error(t, err)
+
+	_, err = v.Resolve("file:///db")
+	assert.Error(t, err)
+}
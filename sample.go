@@ -0,0 +1,175 @@
+package koanf
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+
+	"github.com/knadh/koanf/maps"
+)
+
+// sampleMapKey is the key of the example element generated
+// for maps of structs.
+const sampleMapKey = "example"
+
+// sampler generates an example config from the fields of a struct.
+type sampler struct {
+	*defaulter
+
+	keys     []string
+	comments map[string]string
+}
+
+// Sample generates an example config with all the fields of the struct o and
+// returns it marshalled with the parser p, for instance, to print a default
+// config file or to document the config. Fields take the values in their
+// default tags (see UnmarshalConf.Defaults), or their zero values. Slices and
+// maps of structs get one example element, keyed `example` in maps. If p
+// implements Annotator, the keys are written in the order of the fields with
+// the descriptions in the fields' `desc` tags as comments above them.
+// The Tag, DefaultTag, FlatPaths, and DecoderConfig.Squash options in c
+// are used to read the tags.
+//
+//	type Config struct {
+//		Addr    string        `koanf:"addr" default:":8080" desc:"Address to listen on."`
+//		Timeout time.Duration `koanf:"timeout" default:"5s" desc:"Request timeout."`
+//	}
+//
+//	b, err := koanf.Sample(Config{}, ".", yaml.Parser(), koanf.UnmarshalConf{})
+func Sample(o any, delim string, p Parser, c UnmarshalConf) ([]byte, error) {
+	if p == nil {
+		return nil, errors.New("sample received a nil parser")
+	}
+
+	t := reflect.TypeOf(o)
+	for t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct || isText(t) {
+		return nil, fmt.Errorf("sample requires a struct, got %T", o)
+	}
+
+	s := &sampler{
+		defaulter: newDefaulter(c, delim),
+		comments:  make(map[string]string),
+	}
+	mp, err := s.structMap(t, "")
+	if err != nil {
+		return nil, err
+	}
+	if c.FlatPaths {
+		mp = maps.Unflatten(mp, delim)
+	}
+
+	if a, ok := p.(Annotator); ok {
+		return a.MarshalAnnotated(mp, s.keys, s.comments, delim)
+	}
+	return p.Marshal(mp)
+}
+
+// structMap returns the example config map of the struct type t
+// and records the keys and descriptions of its fields.
+func (s *sampler) structMap(t reflect.Type, key string) (map[string]any, error) {
+	s.visiting[t] = true
+	defer delete(s.visiting, t)
+
+	out := make(map[string]any)
+	err := s.eachField(t, func(name string, f reflect.StructField) error {
+		fkey := joinKey(key, name, s.delim)
+
+		var (
+			val any
+			err error
+		)
+		if def, ok := f.Tag.Lookup(s.defTag); ok {
+			if val, err = parseDefault(def, f.Type); err != nil {
+				return fmt.Errorf("invalid default for %s: %w", fkey, err)
+			}
+		} else if val, err = s.zero(f.Type, fkey); err != nil {
+			return err
+		}
+
+		// Fields that can't be represented in config, like funcs,
+		// and recursive structs are skipped.
+		if val == nil {
+			return nil
+		}
+
+		out[name] = val
+		s.keys = append(s.keys, fkey)
+		if desc := f.Tag.Get("desc"); desc != "" {
+			s.comments[fkey] = desc
+		}
+		return nil
+	})
+
+	return out, err
+}
+
+// zero returns the example value of a field of the type t without a default.
+func (s *sampler) zero(t reflect.Type, key string) (any, error) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	if isText(t) {
+		return "", nil
+	}
+	if t == durationType {
+		return "0s", nil
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		if s.visiting[t] {
+			return nil, nil
+		}
+		return s.structMap(t, key)
+
+	case reflect.Slice, reflect.Array:
+		if !isStruct(t.Elem()) {
+			return []any{}, nil
+		}
+		v, err := s.zero(t.Elem(), joinKey(key, "0", s.delim))
+		if err != nil || v == nil {
+			return []any{}, err
+		}
+		return []any{v}, nil
+
+	case reflect.Map:
+		if !isStruct(t.Elem()) {
+			return map[string]any{}, nil
+		}
+		v, err := s.zero(t.Elem(), joinKey(key, sampleMapKey, s.delim))
+		if err != nil || v == nil {
+			return map[string]any{}, err
+		}
+		return map[string]any{sampleMapKey: v}, nil
+
+	case reflect.String, reflect.Interface:
+		return "", nil
+
+	case reflect.Bool:
+		return false, nil
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return int64(0), nil
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return uint64(0), nil
+
+	case reflect.Float32, reflect.Float64:
+		return float64(0), nil
+	}
+
+	return nil, nil
+}
+
+// isStruct checks whether the type t, or the type it points to,
+// is a struct that's unmarshalled from a map.
+func isStruct(t reflect.Type) bool {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t.Kind() == reflect.Struct && !isText(t)
+}
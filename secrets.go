@@ -0,0 +1,316 @@
+package koanf
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/knadh/koanf/maps"
+)
+
+// RedactedValue replaces the values of sensitive keys in the output of
+// Sprint(), Print(), All(), and MarshalRedacted().
+const RedactedValue = "******"
+
+// IsSensitive checks whether the value of the given key path, or of one of
+// its parents, is sensitive: it matches a pattern in Conf.Sensitive, was
+// resolved from a secret reference, or was loaded with WithSensitive or from
+// a SensitiveProvider.
+func (ko *Koanf) IsSensitive(path string) bool {
+	return ko.isSensitive(ko.st.Load(), path)
+}
+
+// SensitiveKeys returns the sorted flattened key paths whose values
+// are sensitive, including the key paths of the elements of slices,
+// addressed by their index, like `dbs.0.password`. See IsSensitive().
+func (ko *Koanf) SensitiveKeys() []string {
+	st := ko.st.Load()
+	out := []string{}
+	for k, v := range st.confMapFlat {
+		ko.walkSensitive(st, k, v, func(path string, _ any) {
+			out = append(out, path)
+		})
+	}
+
+	sort.Strings(out)
+	return out
+}
+
+// walkSensitive calls fn for the sensitive key paths in v, which is the value
+// of the key path, including the elements of slices, with their values.
+func (ko *Koanf) walkSensitive(st *state, path string, v any, fn func(path string, v any)) {
+	if mp, ok := v.(map[string]any); ok && len(mp) > 0 {
+		for k, e := range mp {
+			ko.walkSensitive(st, joinKey(path, maps.QuoteKey(k, ko.conf.Delim), ko.conf.Delim), e, fn)
+		}
+		return
+	}
+
+	if ko.isSensitive(st, path) {
+		fn(path, v)
+		return
+	}
+	if sl, ok := v.([]any); ok {
+		for i, e := range sl {
+			ko.walkSensitive(st, joinKey(path, strconv.Itoa(i), ko.conf.Delim), e, fn)
+		}
+	}
+}
+
+// sensitiveUnder returns a function that checks whether a key path relative
+// to the given key path is sensitive in the state, for derive().
+func (ko *Koanf) sensitiveUnder(st *state, path string) func(key string, v any) bool {
+	return func(key string, _ any) bool {
+		return ko.isSensitive(st, joinKey(path, key, ko.conf.Delim))
+	}
+}
+
+// sensitiveValues returns a function that checks whether a value is, or
+// contains, one of the non-empty string values of the sensitive keys in the
+// state, for derive() with values whose key paths aren't known, like the
+// results of Eval().
+func (ko *Koanf) sensitiveValues(st *state) func(key string, v any) bool {
+	vals := make(map[string]struct{})
+	for k, v := range st.confMapFlat {
+		ko.walkSensitive(st, k, v, func(_ string, v any) {
+			walkStrings(v, func(s string) bool {
+				vals[s] = struct{}{}
+				return false
+			})
+		})
+	}
+
+	return func(_ string, v any) bool {
+		return walkStrings(v, func(s string) bool {
+			_, ok := vals[s]
+			return ok
+		})
+	}
+}
+
+// walkStrings calls fn for the non-empty strings in v, including those in
+// maps and slices, until fn returns true, and returns whether it did.
+func walkStrings(v any, fn func(s string) bool) bool {
+	switch v := v.(type) {
+	case string:
+		return v != "" && fn(v)
+	case map[string]any:
+		for _, e := range v {
+			if walkStrings(e, fn) {
+				return true
+			}
+		}
+	case []any:
+		for _, e := range v {
+			if walkStrings(e, fn) {
+				return true
+			}
+		}
+	case []string:
+		for _, e := range v {
+			if e != "" && fn(e) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// isSensitive is IsSensitive() for the given state.
+func (ko *Koanf) isSensitive(st *state, path string) bool {
+	if len(st.sensitive) == 0 && len(ko.conf.Sensitive) == 0 {
+		return false
+	}
+
+	// Check the path and its parents.
+	parts := maps.SplitKey(ko.key(st, path), ko.conf.Delim)
+	for i := len(parts); i > 0; i-- {
+		path := maps.JoinKey(parts[:i], ko.conf.Delim)
+		if _, ok := st.sensitive[path]; ok {
+			return true
+		}
+		if _, ok := st.sensitiveRefs[path]; ok {
+			return true
+		}
+		for _, p := range ko.conf.Sensitive {
+			if ko.conf.CaseInsensitive {
+				if maps.MatchKey(strings.ToLower(p), strings.ToLower(path), ko.conf.Delim) {
+					return true
+				}
+			} else if maps.MatchKey(p, path, ko.conf.Delim) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// redacted returns a copy of the nested config map with the values
+// of sensitive keys replaced with RedactedValue.
+func (ko *Koanf) redacted() map[string]any {
+	st := ko.st.Load()
+	out := make(map[string]any, len(st.confMap))
+	for k, v := range st.confMap {
+		out[k] = ko.redact(st, maps.QuoteKey(k, ko.conf.Delim), v)
+	}
+	return out
+}
+
+// redact returns a copy of v, which is the value of the key path, with the
+// sensitive values in it, including the elements of slices addressed by
+// their index, replaced with RedactedValue. v is not modified.
+func (ko *Koanf) redact(st *state, path string, v any) any {
+	if mp, ok := v.(map[string]any); ok && len(mp) > 0 {
+		out := make(map[string]any, len(mp))
+		for k, e := range mp {
+			out[k] = ko.redact(st, joinKey(path, maps.QuoteKey(k, ko.conf.Delim), ko.conf.Delim), e)
+		}
+		return out
+	}
+
+	if ko.isSensitive(st, path) {
+		return RedactedValue
+	}
+	if sl, ok := v.([]any); ok {
+		out := make([]any, len(sl))
+		for i, e := range sl {
+			out[i] = ko.redact(st, joinKey(path, strconv.Itoa(i), ko.conf.Delim), e)
+		}
+		return out
+	}
+	return v
+}
+
+// resolveSecrets replaces the secret references in the string values of
+// the config map in place with the values returned by the resolvers in
+// Conf.Resolvers, and returns the key paths of the resolved values.
+func (ko *Koanf) resolveSecrets(mp map[string]any) ([]string, error) {
+	if len(ko.conf.Resolvers) == 0 {
+		return nil, nil
+	}
+
+	var keys []string
+	for k, v := range mp {
+		r, err := ko.resolveSecret(maps.QuoteKey(k, ko.conf.Delim), v, &keys)
+		if err != nil {
+			return nil, err
+		}
+		mp[k] = r
+	}
+	return keys, nil
+}
+
+// resolveSecret resolves the secret references in the value of the given key path.
+func (ko *Koanf) resolveSecret(key string, v any, keys *[]string) (any, error) {
+	switch v := v.(type) {
+	case string:
+		scheme, _, ok := strings.Cut(v, "://")
+		if !ok {
+			return v, nil
+		}
+		r, ok := ko.conf.Resolvers[scheme]
+		if !ok {
+			return v, nil
+		}
+
+		out, err := r.Resolve(v)
+		if err != nil {
+			return nil, fmt.Errorf("error resolving secret reference in %s: %w", key, err)
+		}
+		*keys = append(*keys, key)
+
+		// Secrets are not references to be interpolated.
+		if ko.conf.Interpolate {
+			out = escapeValue(out)
+		}
+		return out, nil
+
+	case map[string]any:
+		for k, c := range v {
+			r, err := ko.resolveSecret(joinKey(key, maps.QuoteKey(k, ko.conf.Delim), ko.conf.Delim), c, keys)
+			if err != nil {
+				return nil, err
+			}
+			v[k] = r
+		}
+
+	case []any:
+		for i, c := range v {
+			r, err := ko.resolveSecret(key, c, keys)
+			if err != nil {
+				return nil, err
+			}
+			v[i] = r
+		}
+	}
+
+	return v, nil
+}
+
+// markSensitive marks the key paths of the resolved secrets, or if all is
+// true, all the keys in the merged config map c as sensitive, and unmarks the
+// other keys that c overwrote in an unpublished state.
+func (ko *Koanf) markSensitive(st *state, c map[string]any, secrets []string, all bool) {
+	fl, _ := maps.Flatten(c, nil, ko.conf.Delim)
+	for k := range fl {
+		if all {
+			st.sensitive[k] = struct{}{}
+		} else {
+			delete(st.sensitive, k)
+		}
+	}
+	for _, k := range secrets {
+		st.sensitive[ko.key(st, k)] = struct{}{}
+	}
+
+	st.pruneSensitive()
+}
+
+// markRefs marks the keys whose values reference sensitive keys, or keys
+// with sensitive keys under them, with ${...}, directly or through other
+// references, as sensitive. For instance, `dsn: postgres://u:${db.password}@h`.
+func (ko *Koanf) markRefs(st *state) {
+	st.sensitiveRefs = nil
+	if len(st.refs) == 0 || (len(st.sensitive) == 0 && len(ko.conf.Sensitive) == 0) {
+		return
+	}
+
+	st.sensitiveRefs = make(map[string]struct{})
+	for changed := true; changed; {
+		changed = false
+		for k, refs := range st.refs {
+			if _, ok := st.sensitiveRefs[k]; ok {
+				continue
+			}
+			for _, r := range refs {
+				if ko.hasSensitive(st, r) {
+					st.sensitiveRefs[k] = struct{}{}
+					changed = true
+					break
+				}
+			}
+		}
+	}
+}
+
+// hasSensitive checks whether the key path, one of its parents, or one
+// of the keys or slice elements under it is sensitive.
+func (ko *Koanf) hasSensitive(st *state, path string) bool {
+	key := ko.key(st, path)
+
+	found := false
+	ko.walkSensitive(st, key, maps.Search(st.confMap, maps.SplitKey(key, ko.conf.Delim)), func(string, any) {
+		found = true
+	})
+	return found
+}
+
+// pruneSensitive unmarks the keys that no longer exist in the config map.
+func (st *state) pruneSensitive() {
+	for k := range st.sensitive {
+		if _, ok := st.keyMap[k]; !ok {
+			delete(st.sensitive, k)
+		}
+	}
+}
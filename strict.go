@@ -0,0 +1,215 @@
+package koanf
+
+import (
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/knadh/koanf/maps"
+)
+
+// StrictError is returned by UnmarshalWithConf when UnmarshalConf.Strict is
+// set, listing the keys in the config that were not unmarshalled into the
+// struct, or when UnmarshalConf.StrictUnset is set, listing the struct fields
+// that got no value. The struct is unmarshalled even if the error is returned.
+type StrictError struct {
+	// Unused are the full delimited key paths in the config that don't match
+	// any struct field, for instance, `databse.host`. Keys of elements of
+	// slices and maps are addressed by their index or map key.
+	Unused []string
+
+	// Unset are the full delimited key paths of the struct fields that are
+	// absent from the config. It's only set with UnmarshalConf.StrictUnset.
+	Unset []string
+}
+
+// Error returns the unused keys and unset fields as a single string.
+func (e *StrictError) Error() string {
+	var s []string
+	if len(e.Unused) > 0 {
+		s = append(s, "unused keys: "+strings.Join(e.Unused, ", "))
+	}
+	if len(e.Unset) > 0 {
+		s = append(s, "unset fields: "+strings.Join(e.Unset, ", "))
+	}
+	return "invalid config: " + strings.Join(s, "; ")
+}
+
+// checkStrict returns a *StrictError listing the keys in the config value v
+// that the type t doesn't consume if unused is true, and the fields of t that
+// are absent from v if unset is true. key is the full key path of v.
+func (d *defaulter) checkStrict(v any, t reflect.Type, key string, unused, unset bool) error {
+	e := &StrictError{}
+	if unused {
+		d.unused(v, t, key, &e.Unused)
+		sort.Strings(e.Unused)
+	}
+	if unset {
+		d.unset(v, t, key, &e.Unset)
+	}
+
+	if len(e.Unused) > 0 || len(e.Unset) > 0 {
+		return e
+	}
+	return nil
+}
+
+// unused appends the full key paths in v that are not consumed
+// by the type t to out.
+func (d *defaulter) unused(v any, t reflect.Type, key string, out *[]string) {
+	if t == nil {
+		return
+	}
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if isText(t) {
+		return
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		mp, ok := v.(map[string]any)
+		if !ok {
+			return
+		}
+
+		used := make(map[string]bool, len(mp))
+		_ = d.eachField(t, func(name string, f reflect.StructField) error {
+			k, ok := lookupKey(mp, name)
+			if !ok {
+				return nil
+			}
+			used[k] = true
+
+			// With flat paths, the tags hold complete key paths and there is no nesting.
+			if !d.flat {
+				d.unused(mp[k], f.Type, joinKey(key, maps.QuoteKey(k, d.delim), d.delim), out)
+			}
+			return nil
+		})
+
+		// A `remain` field consumes all the keys that don't match other fields.
+		if hasRemain(t, d.tag) {
+			return
+		}
+		for _, k := range sortedKeys(mp) {
+			if used[k] {
+				continue
+			}
+			// Flat keys are complete key paths.
+			if !d.flat {
+				k = maps.QuoteKey(k, d.delim)
+			}
+			*out = append(*out, joinKey(key, k, d.delim))
+		}
+
+	case reflect.Slice, reflect.Array:
+		sl, ok := v.([]any)
+		if !ok {
+			return
+		}
+		for i, val := range sl {
+			d.unused(val, t.Elem(), joinKey(key, strconv.Itoa(i), d.delim), out)
+		}
+
+	case reflect.Map:
+		mp, ok := v.(map[string]any)
+		if !ok {
+			return
+		}
+		for _, k := range sortedKeys(mp) {
+			d.unused(mp[k], t.Elem(), joinKey(key, maps.QuoteKey(k, d.delim), d.delim), out)
+		}
+	}
+}
+
+// unset appends the full key paths of the fields of the struct type t, and
+// of the structs in it, that are absent from v to out. Fields of nested
+// structs that are absent are listed individually.
+func (d *defaulter) unset(v any, t reflect.Type, key string, out *[]string) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if !isStruct(t) {
+		return
+	}
+
+	mp, _ := v.(map[string]any)
+	if mp == nil {
+		if d.visiting[t] {
+			return
+		}
+		d.visiting[t] = true
+		defer delete(d.visiting, t)
+	}
+
+	_ = d.eachField(t, func(name string, f reflect.StructField) error {
+		k, ok := lookupKey(mp, name)
+		if !ok {
+			fkey := joinKey(key, name, d.delim)
+			if isStruct(f.Type) && !d.flat {
+				d.unset(nil, f.Type, fkey, out)
+			} else {
+				*out = append(*out, fkey)
+			}
+			return nil
+		}
+		if d.flat {
+			return nil
+		}
+
+		var (
+			fkey = joinKey(key, maps.QuoteKey(k, d.delim), d.delim)
+			ft   = f.Type
+		)
+		for ft.Kind() == reflect.Pointer {
+			ft = ft.Elem()
+		}
+		switch ft.Kind() {
+		case reflect.Struct:
+			if m, ok := mp[k].(map[string]any); ok {
+				d.unset(m, ft, fkey, out)
+			}
+
+		case reflect.Slice, reflect.Array:
+			sl, _ := mp[k].([]any)
+			for i, val := range sl {
+				if m, ok := val.(map[string]any); ok {
+					d.unset(m, ft.Elem(), joinKey(fkey, strconv.Itoa(i), d.delim), out)
+				}
+			}
+
+		case reflect.Map:
+			m, _ := mp[k].(map[string]any)
+			for _, mk := range sortedKeys(m) {
+				if val, ok := m[mk].(map[string]any); ok {
+					d.unset(val, ft.Elem(), joinKey(fkey, maps.QuoteKey(mk, d.delim), d.delim), out)
+				}
+			}
+		}
+		return nil
+	})
+}
+
+// hasRemain checks whether the struct type t has a field with
+// the `remain` option in its tag.
+func hasRemain(t reflect.Type, tag string) bool {
+	for i := 0; i < t.NumField(); i++ {
+		_, opts, _ := strings.Cut(t.Field(i).Tag.Get(tag), ",")
+		if hasTagOpt(opts, "remain") {
+			return true
+		}
+	}
+	return false
+}
+
+func sortedKeys(mp map[string]any) []string {
+	out := make([]string, 0, len(mp))
+	for k := range mp {
+		out = append(out, k)
+	}
+	sort.Strings(out)
+	return out
+}
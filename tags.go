@@ -0,0 +1,359 @@
+package koanf
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/knadh/koanf/maps"
+)
+
+// FieldViolation is a struct field that violates a rule in its validation tag.
+type FieldViolation struct {
+	// Key is the full delimited key path of the field, for instance, `db.port`.
+	// Elements of slices and maps are addressed by their index or map key,
+	// for instance, `servers.0.host`.
+	Key string
+
+	// Rule is the violated rule as it appears in the tag, for instance, `max=65535`.
+	Rule string
+
+	// Message describes the violation.
+	Message string
+}
+
+// ValidationError is returned by UnmarshalWithConf when UnmarshalConf.Validate
+// is set, listing every struct field that violates its validation tag.
+type ValidationError struct {
+	Violations []FieldViolation
+}
+
+// Error returns all violations as a single string.
+func (e *ValidationError) Error() string {
+	s := make([]string, 0, len(e.Violations))
+	for _, v := range e.Violations {
+		s = append(s, v.Key+": "+v.Message)
+	}
+	return "invalid config: " + strings.Join(s, "; ")
+}
+
+var (
+	durationType = reflect.TypeOf(time.Duration(0))
+
+	// regexCache caches the compiled regex rules of validation tags.
+	regexCache sync.Map
+)
+
+// validateStruct validates the fields of the unmarshalled struct o against
+// the rules in their validation tags and returns a *ValidationError listing
+// all the violations. path is the key path that o was unmarshalled from.
+func (ko *Koanf) validateStruct(path string, o any, c UnmarshalConf) error {
+	tag := c.ValidateTag
+	if tag == "" {
+		tag = "validate"
+	}
+
+	// mapstructure matches keys to field names case-insensitively.
+	keys := make(map[string]struct{})
+	for k := range ko.KeyMap() {
+		keys[strings.ToLower(k)] = struct{}{}
+	}
+
+	var out []FieldViolation
+	walkStruct(reflect.ValueOf(o), path, ko.conf.Delim, c, func(key string, f reflect.StructField, v reflect.Value) {
+		rules := f.Tag.Get(tag)
+		if rules == "" {
+			return
+		}
+
+		_, set := keys[strings.ToLower(key)]
+		out = append(out, checkRules(key, rules, v, set)...)
+	})
+
+	if len(out) > 0 {
+		return &ValidationError{Violations: out}
+	}
+	return nil
+}
+
+// walkStruct calls fn for every exported field of the struct (or pointer to
+// struct) v with the full key path of the field as it's matched by mapstructure.
+// It descends into nested structs, and slices, arrays, and maps of structs.
+func walkStruct(v reflect.Value, path, delim string, c UnmarshalConf, fn func(key string, f reflect.StructField, v reflect.Value)) {
+	v = deref(v)
+	if v.Kind() != reflect.Struct {
+		return
+	}
+
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+
+		name, opts, _ := strings.Cut(f.Tag.Get(c.DecoderConfig.TagName), ",")
+		if name == "-" || hasTagOpt(opts, "remain") {
+			continue
+		}
+
+		// Embedded structs that are squashed share the parent's path.
+		if f.Anonymous && (c.DecoderConfig.Squash || hasTagOpt(opts, "squash")) {
+			walkStruct(v.Field(i), path, delim, c, fn)
+			continue
+		}
+
+		if name == "" {
+			name = f.Name
+		}
+		key := joinKey(path, name, delim)
+
+		fv := v.Field(i)
+		fn(key, f, fv)
+
+		// With flat paths, the tags hold complete key paths and there is no nesting.
+		if c.FlatPaths {
+			continue
+		}
+		walkValue(fv, key, delim, c, fn)
+	}
+}
+
+// walkValue walks the structs in the value v, which may be a struct,
+// or a slice, array, or map of structs.
+func walkValue(v reflect.Value, key, delim string, c UnmarshalConf, fn func(key string, f reflect.StructField, v reflect.Value)) {
+	v = deref(v)
+	switch v.Kind() {
+	case reflect.Struct:
+		walkStruct(v, key, delim, c, fn)
+
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			walkValue(v.Index(i), joinKey(key, strconv.Itoa(i), delim), delim, c, fn)
+		}
+
+	case reflect.Map:
+		iter := v.MapRange()
+		for iter.Next() {
+			walkValue(iter.Value(), joinKey(key, maps.QuoteKey(fmt.Sprint(iter.Key().Interface()), delim), delim), delim, c, fn)
+		}
+	}
+}
+
+// checkRules checks the value of a field against the comma separated rules
+// in its validation tag. set indicates whether the key was present in the config.
+// Rules other than `required` are skipped for fields that are neither set in
+// the config nor have a non-zero value.
+func checkRules(key, rules string, v reflect.Value, set bool) []FieldViolation {
+	v = deref(v)
+	present := set || (v.IsValid() && !v.IsZero())
+
+	var out []FieldViolation
+	for rules != "" {
+		var rule string
+
+		// The regex rule consumes the rest of the tag as the pattern can contain commas.
+		if strings.HasPrefix(rules, "regex=") {
+			rule, rules = rules, ""
+		} else {
+			rule, rules, _ = strings.Cut(rules, ",")
+		}
+
+		rule = strings.TrimSpace(rule)
+		if rule == "" {
+			continue
+		}
+
+		name, arg, _ := strings.Cut(rule, "=")
+		if name == "required" {
+			if !present {
+				out = append(out, FieldViolation{Key: key, Rule: rule, Message: "is required"})
+			}
+			continue
+		}
+
+		if !present || !v.IsValid() {
+			continue
+		}
+
+		if msg := checkRule(name, arg, v); msg != "" {
+			out = append(out, FieldViolation{Key: key, Rule: rule, Message: msg})
+		}
+	}
+
+	return out
+}
+
+// checkRule checks v against a single rule and returns the violation
+// message, or an empty string if v is valid.
+func checkRule(name, arg string, v reflect.Value) string {
+	switch name {
+	case "min":
+		return checkBound(v, arg, true)
+
+	case "max":
+		return checkBound(v, arg, false)
+
+	case "oneof":
+		s := fmt.Sprint(v.Interface())
+		opts := strings.Fields(arg)
+		for _, o := range opts {
+			if s == o {
+				return ""
+			}
+		}
+		return fmt.Sprintf("must be one of [%s]", strings.Join(opts, ", "))
+
+	case "regex":
+		re, err := compileRegex(arg)
+		if err != nil {
+			return fmt.Sprintf("invalid regex rule: %v", err)
+		}
+		if !re.MatchString(fmt.Sprint(v.Interface())) {
+			return fmt.Sprintf("must match %s", arg)
+		}
+
+	case "url":
+		u, err := url.Parse(fmt.Sprint(v.Interface()))
+		if err != nil || u.Scheme == "" || u.Host == "" {
+			return "must be a URL"
+		}
+
+	case "hostport":
+		_, port, err := net.SplitHostPort(fmt.Sprint(v.Interface()))
+		if err != nil {
+			return "must be a host:port address"
+		}
+		if _, err := strconv.ParseUint(port, 10, 16); err != nil {
+			return "must be a host:port address with a numeric port"
+		}
+
+	default:
+		return fmt.Sprintf("unknown validation rule %s", name)
+	}
+
+	return ""
+}
+
+// checkBound checks v against a min (or max) bound. Numbers are compared by
+// value, time.Duration against a duration string (eg: 1s), and strings, slices,
+// and maps by their length.
+func checkBound(v reflect.Value, arg string, isMin bool) string {
+	var (
+		cmp int
+		err error
+		pre = ""
+	)
+
+	switch v.Kind() {
+	case reflect.String, reflect.Slice, reflect.Array, reflect.Map:
+		var n int
+		n, err = strconv.Atoi(arg)
+
+		l := v.Len()
+		if v.Kind() == reflect.String {
+			l = utf8.RuneCountInString(v.String())
+		}
+		cmp = compare(int64(l), int64(n))
+		pre = "length "
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		var n int64
+		if v.Type() == durationType {
+			var d time.Duration
+			d, err = time.ParseDuration(arg)
+			n = int64(d)
+		} else {
+			n, err = strconv.ParseInt(arg, 10, 64)
+		}
+		cmp = compare(v.Int(), n)
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		var n uint64
+		n, err = strconv.ParseUint(arg, 10, 64)
+		cmp = compare(v.Uint(), n)
+
+	case reflect.Float32, reflect.Float64:
+		var n float64
+		n, err = strconv.ParseFloat(arg, 64)
+		cmp = compare(v.Float(), n)
+
+	default:
+		return fmt.Sprintf("min and max are not supported for %s", v.Type())
+	}
+
+	if err != nil {
+		return fmt.Sprintf("invalid bound %s: %v", arg, err)
+	}
+
+	if isMin && cmp < 0 {
+		return fmt.Sprintf("%smust be at least %s", pre, arg)
+	}
+	if !isMin && cmp > 0 {
+		return fmt.Sprintf("%smust be at most %s", pre, arg)
+	}
+	return ""
+}
+
+// compare returns -1, 0, or 1 if a is less than, equal to, or greater than b.
+func compare[T int64 | uint64 | float64](a, b T) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// compileRegex returns the compiled regex pattern from the cache,
+// compiling it on first use.
+func compileRegex(pattern string) (*regexp.Regexp, error) {
+	if re, ok := regexCache.Load(pattern); ok {
+		return re.(*regexp.Regexp), nil
+	}
+
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+	regexCache.Store(pattern, re)
+	return re, nil
+}
+
+// deref dereferences pointers and interfaces. It returns an invalid
+// Value for nil pointers.
+func deref(v reflect.Value) reflect.Value {
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return reflect.Value{}
+		}
+		v = v.Elem()
+	}
+	return v
+}
+
+// hasTagOpt checks whether the comma separated struct tag options contain opt.
+func hasTagOpt(opts, opt string) bool {
+	for _, o := range strings.Split(opts, ",") {
+		if o == opt {
+			return true
+		}
+	}
+	return false
+}
+
+// joinKey joins a key to a key path with the delimiter.
+func joinKey(path, key, delim string) string {
+	if path == "" {
+		return key
+	}
+	return path + delim + key
+}
@@ -0,0 +1,137 @@
+package koanf_test
+
+import (
+	"testing"
+
+	"github.com/knadh/koanf/providers/confmap"
+	"github.com/knadh/koanf/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAliases(t *testing.T) {
+	var deps []koanf.Deprecation
+	k := koanf.NewWithConf(koanf.Conf{
+		Delim: delim,
+		Aliases: map[string]string{
+			"db.addr":  "database.host",
+			"db.port":  "database.port",
+			"old_tls":  "server.tls",
+			"timeout":  "server.timeout",
+			"nonexist": "x",
+		},
+		Provenance: true,
+		OnDeprecated: func(d koanf.Deprecation) {
+			deps = append(deps, d)
+		},
+	})
+
+	p := confmap.Provider(map[string]any{
+		"db":       map[string]any{"addr": "localhost", "port": 5432},
+		"old_tls":  map[string]any{"cert": "a.pem"},
+		"database": map[string]any{"user": "app"},
+	}, delim)
+	require.NoError(t, k.Load(p, nil))
+
+	assert.Equal(t, map[string]any{
+		"database": map[string]any{"host": "localhost", "port": 5432, "user": "app"},
+		"server":   map[string]any{"tls": map[string]any{"cert": "a.pem"}},
+	}, k.Raw())
+	assert.False(t, k.Exists("db"))
+	assert.Equal(t, []koanf.Deprecation{
+		{Key: "db.addr", NewKey: "database.host"},
+		{Key: "db.port", NewKey: "database.port"},
+		{Key: "old_tls", NewKey: "server.tls"},
+	}, deps)
+
+	// The provider's map isn't modified, so reloading warns again.
+	deps = nil
+	require.NoError(t, k.Load(p, nil))
+	assert.Len(t, deps, 3)
+
+	// Set with a deprecated key.
+	deps = nil
+	require.NoError(t, k.Set("timeout", "5s"))
+	assert.Equal(t, "5s", k.String("server.timeout"))
+	assert.False(t, k.Exists("timeout"))
+	assert.Equal(t, []koanf.Deprecation{{Key: "timeout", NewKey: "server.timeout"}}, deps)
+
+	// A later load overrides the new key with the deprecated one.
+	deps = nil
+	require.NoError(t, k.Load(confmap.Provider(map[string]any{"db.addr": "example.com"}, delim), nil))
+	assert.Equal(t, "example.com", k.String("database.host"))
+	assert.Equal(t, []koanf.Deprecation{{Key: "db.addr", NewKey: "database.host"}}, deps)
+
+	// Provenance is recorded for the new key.
+	e := k.Explain("database.host")
+	require.GreaterOrEqual(t, e.Winner, 0)
+	assert.Equal(t, "example.com", e.Origins[e.Winner].Value)
+}
+
+func TestAliasesCut(t *testing.T) {
+	var deps []koanf.Deprecation
+	k := koanf.NewWithConf(koanf.Conf{
+		Delim:   delim,
+		Aliases: map[string]string{"host": "addr"},
+		OnDeprecated: func(d koanf.Deprecation) {
+			deps = append(deps, d)
+		},
+	})
+
+	// Deprecated keys are only moved in the config being loaded, and
+	// not in copies of the config, for instance, under a path.
+	require.NoError(t, k.Load(confmap.Provider(map[string]any{
+		"replica.host": "r1",
+		"replicas":     []any{map[string]any{"host": "r2"}},
+	}, delim), nil))
+	assert.Empty(t, deps)
+
+	assert.Equal(t, map[string]any{"host": "r1"}, k.Cut("replica").Raw())
+	assert.Equal(t, k.Raw(), k.Copy().Raw())
+	assert.Equal(t, map[string]any{"host": "r2"}, k.Slices("replicas")[0].Raw())
+	assert.Empty(t, deps)
+}
+
+func TestAliasConflict(t *testing.T) {
+	var deps []koanf.Deprecation
+	conf := koanf.Conf{
+		Delim:   delim,
+		Aliases: map[string]string{"db.addr": "database.host"},
+		OnDeprecated: func(d koanf.Deprecation) {
+			deps = append(deps, d)
+		},
+	}
+	mp := map[string]any{
+		"db.addr":       "old",
+		"database.host": "new",
+	}
+
+	// By default, the new key wins and the conflict is reported.
+	k := koanf.NewWithConf(conf)
+	require.NoError(t, k.Load(confmap.Provider(mp, delim), nil))
+	assert.Equal(t, "new", k.String("database.host"))
+	assert.False(t, k.Exists("db"))
+	assert.Equal(t, []koanf.Deprecation{{Key: "db.addr", NewKey: "database.host", Conflict: true}}, deps)
+
+	// The error policy rejects the merge.
+	deps = nil
+	conf.AliasConflict = koanf.AliasConflictError
+	k = koanf.NewWithConf(conf)
+	err := k.Load(confmap.Provider(mp, delim), nil)
+	assert.ErrorContains(t, err, "deprecated key db.addr")
+	assert.Empty(t, k.Keys())
+	assert.Empty(t, deps)
+}
+
+func TestAliasesCaseInsensitive(t *testing.T) {
+	k := koanf.NewWithConf(koanf.Conf{
+		Delim:           delim,
+		CaseInsensitive: true,
+		Aliases:         map[string]string{"db.addr": "database.host"},
+	})
+	require.NoError(t, k.Load(confmap.Provider(map[string]any{"Database.User": "app"}, delim), nil))
+	require.NoError(t, k.Load(confmap.Provider(map[string]any{"DB.ADDR": "localhost"}, delim), nil))
+
+	assert.Equal(t, "localhost", k.String("database.host"))
+	assert.Equal(t, []string{"Database.User", "Database.host"}, k.Keys())
+}
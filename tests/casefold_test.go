@@ -0,0 +1,69 @@
+package koanf_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/knadh/koanf/parsers/json"
+	"github.com/knadh/koanf/providers/confmap"
+	"github.com/knadh/koanf/providers/env/v2"
+	"github.com/knadh/koanf/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCaseInsensitive(t *testing.T) {
+	assert := assert.New(t)
+	t.Setenv("CI_DB.HOST", "env-host")
+	t.Setenv("CI_DB.POOL.MAX", "20")
+
+	k := koanf.NewWithConf(koanf.Conf{Delim: delim, CaseInsensitive: true})
+	require.NoError(t, k.Load(confmap.Provider(map[string]any{
+		"db.host":       "localhost",
+		"db.userName":   "app",
+		"db.pool.min":   1,
+		"serverTimeout": 10,
+	}, delim), nil))
+	require.NoError(t, k.Load(env.Provider(delim, env.Opt{Prefix: "CI_", TransformFunc: func(k, v string) (string, any) {
+		return strings.TrimPrefix(k, "CI_"), v
+	}}), nil))
+
+	// Keys that differ in case are merged, retaining the casing of the first load.
+	// db.pool.MAX is new and keeps the env casing.
+	assert.Equal([]string{"db.host", "db.pool.MAX", "db.pool.min", "db.userName", "serverTimeout"}, k.Keys())
+	assert.Equal("env-host", k.String("db.host"))
+	assert.Equal("env-host", k.String("DB.HOST"))
+	assert.Equal("app", k.String("DB.USERNAME"))
+	assert.Equal(20, k.Int("db.Pool.Max"))
+	assert.Equal(10, k.Int("servertimeout"))
+	assert.True(k.Exists("Db.Pool"))
+	assert.False(k.Exists("db.nope"))
+	assert.Equal([]string{"MAX", "min"}, k.MapKeys("DB.POOL"))
+	assert.Equal(1, k.Cut("DB").Int("POOL.MIN"))
+
+	b, err := k.Marshal(json.Parser())
+	require.NoError(t, err)
+	assert.JSONEq(`{"db": {"host": "env-host", "userName": "app", "pool": {"min": 1, "MAX": "20"}}, "serverTimeout": 10}`, string(b))
+
+	require.NoError(t, k.Set("DB.USERNAME", "root"))
+	assert.Equal("root", k.Raw()["db"].(map[string]any)["userName"])
+
+	k.Delete("DB.POOL")
+	assert.False(k.Exists("db.pool.min"))
+	assert.Equal([]string{"db.host", "db.userName", "serverTimeout"}, k.Keys())
+
+	// Keys that differ in case within the same load are merged.
+	require.NoError(t, k.Load(confmap.Provider(map[string]any{
+		"Cache": map[string]any{"ttl": 1},
+		"cache": map[string]any{"size": 2},
+	}, delim), nil))
+	assert.Equal(map[string]any{"ttl": 1, "size": 2}, k.Get("CACHE"))
+}
+
+func TestCaseSensitiveDefault(t *testing.T) {
+	k := koanf.New(delim)
+	require.NoError(t, k.Load(confmap.Provider(map[string]any{"db.host": "a", "DB.HOST": "b"}, delim), nil))
+	assert.Equal(t, "a", k.String("db.host"))
+	assert.Equal(t, "b", k.String("DB.HOST"))
+	assert.Equal(t, "", k.String("Db.Host"))
+}
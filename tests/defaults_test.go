@@ -0,0 +1,199 @@
+package koanf_test
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/knadh/koanf/providers/confmap"
+	"github.com/knadh/koanf/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type defBackend struct {
+	Host   string `koanf:"host" default:"localhost"`
+	Port   int    `koanf:"port" default:"80"`
+	Secure bool   `koanf:"secure" default:"true"`
+}
+
+type defBase struct {
+	Env string `koanf:"env" default:"dev"`
+}
+
+type defNode struct {
+	Name string   `koanf:"name" default:"node"`
+	Next *defNode `koanf:"next"`
+}
+
+type defConf struct {
+	defBase `koanf:",squash"`
+
+	Name     string                 `koanf:"name" default:"app"`
+	Timeout  time.Duration          `koanf:"timeout" default:"1m30s"`
+	Ratio    float64                `koanf:"ratio" default:"0.5"`
+	Tags     []string               `koanf:"tags" default:"a, b"`
+	Ports    []int                  `koanf:"ports" default:"80,443"`
+	Limits   map[string]int         `koanf:"limits" default:"read=10,write=5"`
+	IP       net.IP                 `koanf:"ip" default:"127.0.0.1"`
+	Empty    []string               `koanf:"empty" default:""`
+	NoDef    string                 `koanf:"nodef"`
+	Skip     string                 `koanf:"-" default:"skip"`
+	Backend  defBackend             `koanf:"backend"`
+	Ptr      *defBackend            `koanf:"ptr"`
+	Backends []defBackend           `koanf:"backends"`
+	Named    map[string]*defBackend `koanf:"named"`
+	Node     defNode                `koanf:"node"`
+}
+
+func TestUnmarshalDefaults(t *testing.T) {
+	k := koanf.New(delim)
+	require.NoError(t, k.Load(confmap.Provider(map[string]any{
+		"name":         "koanf",
+		"env":          "prod",
+		"backend.port": 8080,
+		"backends":     []any{map[string]any{"host": "a"}, map[string]any{"port": 81}},
+		"named":        map[string]any{"x": map[string]any{"secure": false}},
+		"TAGS":         []string{"x"},
+	}, delim), nil))
+
+	// Without Defaults, the tags are ignored.
+	var c defConf
+	require.NoError(t, k.Unmarshal("", &c))
+	assert.Equal(t, time.Duration(0), c.Timeout)
+	assert.Equal(t, "", c.Backend.Host)
+
+	c = defConf{}
+	require.NoError(t, k.UnmarshalWithConf("", &c, koanf.UnmarshalConf{Defaults: true}))
+
+	// Values in the config take precedence, including case-insensitive matches.
+	assert.Equal(t, "koanf", c.Name)
+	assert.Equal(t, "prod", c.Env)
+	assert.Equal(t, []string{"x"}, c.Tags)
+	assert.Equal(t, 8080, c.Backend.Port)
+
+	assert.Equal(t, 90*time.Second, c.Timeout)
+	assert.Equal(t, 0.5, c.Ratio)
+	assert.Equal(t, []int{80, 443}, c.Ports)
+	assert.Equal(t, map[string]int{"read": 10, "write": 5}, c.Limits)
+	assert.Equal(t, "127.0.0.1", c.IP.String())
+	assert.Equal(t, []string{}, c.Empty)
+	assert.Equal(t, "", c.NoDef)
+	assert.Equal(t, "", c.Skip)
+	assert.Equal(t, defBackend{Host: "localhost", Port: 8080, Secure: true}, c.Backend)
+	assert.Nil(t, c.Ptr, "absent pointers to structs aren't allocated")
+	assert.Equal(t, []defBackend{
+		{Host: "a", Port: 80, Secure: true},
+		{Host: "localhost", Port: 81, Secure: true},
+	}, c.Backends)
+	assert.Equal(t, map[string]*defBackend{"x": {Host: "localhost", Port: 80, Secure: false}}, c.Named)
+	assert.Equal(t, "node", c.Node.Name)
+	assert.Nil(t, c.Node.Next)
+
+	// Pointers to structs in the config get the defaults of their absent fields.
+	require.NoError(t, k.Set("ptr.port", 81))
+	require.NoError(t, k.Set("node.next.next.name", "last"))
+	c = defConf{}
+	require.NoError(t, k.UnmarshalWithConf("", &c, koanf.UnmarshalConf{Defaults: true}))
+	require.NotNil(t, c.Ptr)
+	assert.Equal(t, defBackend{Host: "localhost", Port: 81, Secure: true}, *c.Ptr)
+	require.NotNil(t, c.Node.Next)
+	assert.Equal(t, "node", c.Node.Next.Name)
+	require.NotNil(t, c.Node.Next.Next)
+	assert.Equal(t, "last", c.Node.Next.Next.Name)
+	assert.Nil(t, c.Node.Next.Next.Next)
+	k.Delete("ptr")
+	k.Delete("node")
+
+	// Defaults aren't added to the loaded config.
+	assert.False(t, k.Exists("timeout"))
+
+	// Unmarshalling a path.
+	var b defBackend
+	require.NoError(t, k.UnmarshalWithConf("backend", &b, koanf.UnmarshalConf{Defaults: true}))
+	assert.Equal(t, defBackend{Host: "localhost", Port: 8080, Secure: true}, b)
+
+	// A custom tag.
+	var cu struct {
+		Name string `koanf:"name" def:"x"`
+		Port int    `koanf:"port" def:"1"`
+	}
+	require.NoError(t, k.UnmarshalWithConf("", &cu, koanf.UnmarshalConf{Defaults: true, DefaultTag: "def"}))
+	assert.Equal(t, "koanf", cu.Name)
+	assert.Equal(t, 1, cu.Port)
+}
+
+func TestUnmarshalDefaultsFlat(t *testing.T) {
+	k := koanf.New(delim)
+	require.NoError(t, k.Load(confmap.Provider(map[string]any{
+		"db.host": "db",
+	}, delim), nil))
+
+	var c struct {
+		Host string `koanf:"db.host" default:"localhost"`
+		Port int    `koanf:"db.port" default:"5432"`
+	}
+	require.NoError(t, k.UnmarshalWithConf("", &c, koanf.UnmarshalConf{Defaults: true, FlatPaths: true}))
+	assert.Equal(t, "db", c.Host)
+	assert.Equal(t, 5432, c.Port)
+
+	p := koanf.Defaults(c, delim, koanf.UnmarshalConf{FlatPaths: true})
+	mp, err := p.Read()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"db": map[string]any{"host": "localhost", "port": int64(5432)}}, mp)
+}
+
+func TestUnmarshalDefaultsInvalid(t *testing.T) {
+	k := koanf.New(delim)
+
+	var c struct {
+		DB struct {
+			Timeout time.Duration `koanf:"timeout" default:"soon"`
+		} `koanf:"db"`
+	}
+	err := k.UnmarshalWithConf("", &c, koanf.UnmarshalConf{Defaults: true})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid default for db.timeout")
+
+	var d struct {
+		Port int `koanf:"port" default:"http"`
+	}
+	_, err = koanf.Defaults(&d, delim, koanf.UnmarshalConf{}).Read()
+	assert.ErrorContains(t, err, "invalid default for port")
+}
+
+func TestDefaultsProvider(t *testing.T) {
+	k := koanf.New(delim)
+	require.NoError(t, k.Load(koanf.Defaults(defConf{}, delim, koanf.UnmarshalConf{}), nil))
+	require.NoError(t, k.Load(confmap.Provider(map[string]any{
+		"name":         "koanf",
+		"backend.host": "example.com",
+	}, delim), nil))
+
+	assert.True(t, k.Exists("timeout"))
+	assert.True(t, k.Exists("backend.port"))
+	assert.True(t, k.Exists("env"))
+	assert.True(t, k.Exists("node.name"))
+	assert.False(t, k.Exists("nodef"))
+	assert.False(t, k.Exists("skip"))
+	assert.False(t, k.Exists("backends"))
+	assert.False(t, k.Exists("ptr"))
+	assert.NotContains(t, k.Keys(), "ptr.secure")
+
+	assert.Equal(t, "koanf", k.String("name"))
+	assert.Equal(t, "example.com", k.String("backend.host"))
+	assert.Equal(t, 90*time.Second, k.Duration("timeout"))
+	assert.Equal(t, []string{"a", "b"}, k.Strings("tags"))
+	assert.Equal(t, []int{80, 443}, k.Ints("ports"))
+	assert.Equal(t, map[string]int{"read": 10, "write": 5}, k.IntMap("limits"))
+
+	var c defConf
+	require.NoError(t, k.Unmarshal("", &c))
+	assert.Equal(t, 90*time.Second, c.Timeout)
+	assert.Equal(t, defBackend{Host: "example.com", Port: 80, Secure: true}, c.Backend)
+
+	// Layers.
+	l := koanf.NewLayers(koanf.New(delim))
+	require.NoError(t, l.Add("defaults", koanf.Defaults(&defBackend{}, delim, koanf.UnmarshalConf{}), nil))
+	assert.Equal(t, 80, l.Koanf().Int("port"))
+}
@@ -2,6 +2,7 @@ package koanf_test
 
 import (
 	"fmt"
+	iofs "io/fs"
 	"os"
 	"testing"
 	"testing/fstest"
@@ -123,3 +124,31 @@ func TestFSProvider(t *testing.T) {
 		assert.Equal(time.Date(1970, 1, 1, 0, 20, 34, 0, time.UTC), c.koanf.Time("parent1.id", "").UTC())
 	}
 }
+
+// writeFS is an fstest.MapFS that can write files.
+type writeFS struct {
+	fstest.MapFS
+}
+
+func (w writeFS) WriteFile(name string, data []byte, perm iofs.FileMode) error {
+	w.MapFS[name] = &fstest.MapFile{Data: data, Mode: perm}
+	return nil
+}
+
+func TestFSWrite(t *testing.T) {
+	mfs := writeFS{fstest.MapFS{
+		"conf.json": {Data: []byte("{\n  \"a\": 1,\n  \"b\": 2\n}\n"), Mode: 0o600},
+	}}
+
+	p := fs.Provider(mfs, "conf.json")
+	k := koanf.New(".")
+	require.NoError(t, k.Load(p, json.Parser()))
+	require.NoError(t, k.Set("a", 3))
+	require.NoError(t, k.Save(p, json.Parser()))
+
+	assert.Equal(t, "{\n  \"a\": 3,\n  \"b\": 2\n}\n", string(mfs.MapFS["conf.json"].Data))
+	assert.Equal(t, iofs.FileMode(0o600), mfs.MapFS["conf.json"].Mode)
+
+	// Read-only file systems can't be written to.
+	assert.Error(t, k.Save(fs.Provider(fstest.MapFS{}, "conf.json"), json.Parser()))
+}
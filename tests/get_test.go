@@ -0,0 +1,100 @@
+package koanf_test
+
+import (
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/knadh/koanf/providers/confmap"
+	"github.com/knadh/koanf/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenericGet(t *testing.T) {
+	assert := assert.New(t)
+
+	k := koanf.New(delim)
+	require.NoError(t, k.Load(confmap.Provider(map[string]any{
+		"port":       "8080",
+		"ratio":      0.5,
+		"on":         "true",
+		"timeout":    "1m30s",
+		"ip":         "10.0.0.1",
+		"ports":      []any{80, "443"},
+		"labels":     map[string]any{"a": 1, "b": 2},
+		"db.host":    "localhost",
+		"db.port":    5432,
+		"name":       "app",
+		"nil":        nil,
+		"interfaces": []any{"x", 1},
+	}, delim), nil))
+
+	port, err := koanf.Get[int](k, "port")
+	require.NoError(t, err)
+	assert.Equal(8080, port)
+
+	ratio, err := koanf.Get[float32](k, "ratio")
+	require.NoError(t, err)
+	assert.Equal(float32(0.5), ratio)
+
+	on, err := koanf.Get[bool](k, "on")
+	require.NoError(t, err)
+	assert.True(on)
+
+	d, err := koanf.Get[time.Duration](k, "timeout")
+	require.NoError(t, err)
+	assert.Equal(90*time.Second, d)
+
+	ip, err := koanf.Get[net.IP](k, "ip")
+	require.NoError(t, err)
+	assert.Equal(net.ParseIP("10.0.0.1"), ip)
+
+	ports, err := koanf.Get[[]uint16](k, "ports")
+	require.NoError(t, err)
+	assert.Equal([]uint16{80, 443}, ports)
+
+	labels, err := koanf.Get[map[string]int64](k, "labels")
+	require.NoError(t, err)
+	assert.Equal(map[string]int64{"a": 1, "b": 2}, labels)
+
+	type db struct {
+		Host string `koanf:"host"`
+		Port int    `koanf:"port"`
+	}
+	dbc, err := koanf.Get[db](k, "db")
+	require.NoError(t, err)
+	assert.Equal(db{Host: "localhost", Port: 5432}, dbc)
+
+	ifs, err := koanf.Get[[]any](k, "interfaces")
+	require.NoError(t, err)
+	assert.Equal([]any{"x", 1}, ifs)
+
+	n, err := koanf.Get[string](k, "nil")
+	require.NoError(t, err)
+	assert.Equal("", n)
+
+	// Missing keys.
+	_, err = koanf.Get[int](k, "nope")
+	assert.True(errors.Is(err, koanf.ErrKeyNotFound))
+	assert.ErrorContains(err, "nope")
+
+	// Failed conversions name the key.
+	_, err = koanf.Get[int](k, "name")
+	assert.ErrorContains(err, "error converting name (string) to int")
+	_, err = koanf.Get[time.Duration](k, "name")
+	assert.ErrorContains(err, "name")
+	_, err = koanf.Get[int](k, "db")
+	assert.Error(err)
+
+	// GetOr.
+	assert.Equal(8080, koanf.GetOr(k, "port", 1))
+	assert.Equal(1, koanf.GetOr(k, "nope", 1))
+	assert.Equal(1, koanf.GetOr(k, "name", 1))
+
+	// MustGet.
+	assert.Equal("localhost", koanf.MustGet[string](k, "db.host"))
+	assert.Panics(func() { koanf.MustGet[int](k, "nope") })
+	assert.Panics(func() { koanf.MustGet[int](k, "name") })
+}
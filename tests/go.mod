@@ -3,6 +3,7 @@ module github.com/knadh/koanf/koanf_tests
 go 1.23.0
 
 replace (
+	github.com/knadh/koanf/evaluators/jmespath => ../evaluators/jmespath
 	github.com/knadh/koanf/maps => ../maps
 	github.com/knadh/koanf/parsers/dotenv => ../parsers/dotenv
 	github.com/knadh/koanf/parsers/hcl => ../parsers/hcl
@@ -22,6 +23,7 @@ replace (
 )
 
 require (
+	github.com/knadh/koanf/evaluators/jmespath v0.0.0-00010101000000-000000000000
 	github.com/knadh/koanf/maps v0.1.2
 	github.com/knadh/koanf/parsers/dotenv v0.0.0-00010101000000-000000000000
 	github.com/knadh/koanf/parsers/hcl v0.0.0-00010101000000-000000000000
@@ -0,0 +1,92 @@
+package koanf_test
+
+import (
+	"testing"
+
+	"github.com/knadh/koanf/parsers/yaml"
+	"github.com/knadh/koanf/providers/confmap"
+	"github.com/knadh/koanf/providers/rawbytes"
+	"github.com/knadh/koanf/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInterpolate(t *testing.T) {
+	assert := assert.New(t)
+	t.Setenv("KOANF_TEST_HOME", "/home/koanf")
+	t.Setenv("KOANF_TEST_EMPTY", "")
+
+	k := koanf.NewWithConf(koanf.Conf{Delim: delim, Interpolate: true})
+	require.NoError(t, k.Load(rawbytes.Provider([]byte(`
+server:
+  host: localhost
+  port: 8080
+api:
+  url: "http://${server.host}:${server.port}/api"
+  port: "${server.port}"
+  server: "${server}"
+  hosts: ["${server.host}", "${api.missing:-fallback}"]
+paths:
+  home: "${env:KOANF_TEST_HOME}/app"
+  empty: "${env:KOANF_TEST_EMPTY:-default}"
+  unset: "${env:KOANF_TEST_UNSET:-}"
+  escaped: "$${server.host} ${server.host}"
+  unresolved: "${does.not.exist}"
+`)), yaml.Parser()))
+
+	assert.Equal("http://localhost:8080/api", k.String("api.url"))
+	assert.Equal(8080, k.Get("api.port"), "single references keep the type")
+	assert.Equal(map[string]any{"host": "localhost", "port": 8080}, k.Get("api.server"))
+	assert.Equal([]string{"localhost", "fallback"}, k.Strings("api.hosts"))
+	assert.Equal("/home/koanf/app", k.String("paths.home"))
+	assert.Equal("default", k.String("paths.empty"))
+	assert.Equal("", k.String("paths.unset"))
+	assert.Equal("${server.host} localhost", k.String("paths.escaped"))
+	assert.Equal("${does.not.exist}", k.String("paths.unresolved"))
+
+	// References are resolved again with the values from later loads.
+	require.NoError(t, k.Load(confmap.Provider(map[string]any{
+		"server.host":    "example.com",
+		"does.not.exist": "now",
+	}, delim), nil))
+	assert.Equal("http://example.com:8080/api", k.String("api.url"))
+	assert.Equal("now", k.String("paths.unresolved"))
+	assert.Equal("${server.host} example.com", k.String("paths.escaped"))
+
+	require.NoError(t, k.Set("server.port", 9090))
+	assert.Equal("http://example.com:9090/api", k.String("api.url"))
+
+	k.Delete("does.not.exist")
+	assert.Equal("${does.not.exist}", k.String("paths.unresolved"))
+
+	// Copies retain the resolved values and escaped references.
+	c := k.Copy()
+	assert.Equal("${server.host} example.com", c.String("paths.escaped"))
+	assert.Equal("http://example.com:9090/api", c.String("api.url"))
+	assert.Equal("${server.host} example.com", k.Cut("paths").String("escaped"))
+}
+
+func TestInterpolateCycle(t *testing.T) {
+	k := koanf.NewWithConf(koanf.Conf{Delim: delim, Interpolate: true})
+	require.NoError(t, k.Load(confmap.Provider(map[string]any{"a": "x"}, delim), nil))
+
+	err := k.Load(confmap.Provider(map[string]any{
+		"a":   "${b.c}",
+		"b.c": "prefix-${a}",
+	}, delim), nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "interpolation cycle")
+
+	// The failed load is rejected.
+	assert.Equal(t, "x", k.String("a"))
+	assert.False(t, k.Exists("b.c"))
+
+	// Self references.
+	assert.Error(t, k.Set("a", "${a}"))
+}
+
+func TestInterpolateDisabled(t *testing.T) {
+	k := koanf.New(delim)
+	require.NoError(t, k.Load(confmap.Provider(map[string]any{"a": "x", "b": "${a}"}, delim), nil))
+	assert.Equal(t, "${a}", k.String("b"))
+}
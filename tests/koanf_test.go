@@ -1,6 +1,7 @@
 package koanf_test
 
 import (
+	"context"
 	encjson "encoding/json"
 	"errors"
 	"flag"
@@ -17,6 +18,8 @@ import (
 	"testing"
 	"time"
 
+	"github.com/knadh/koanf/evaluators/jmespath"
+	kmaps "github.com/knadh/koanf/maps"
 	"github.com/knadh/koanf/parsers/dotenv"
 	"github.com/knadh/koanf/parsers/hcl"
 	"github.com/knadh/koanf/parsers/hjson"
@@ -450,7 +453,7 @@ func TestWatchFile(t *testing.T) {
 
 	// Watch for changes.
 	changedC := make(chan error, 1)
-	require.NoError(t, f.Watch(func(event any, err error) {
+	require.NoError(t, f.Watch(context.Background(), func(event koanf.Event, err error) {
 		if err == nil {
 			// Reload the config.
 			err = k.Load(f, json.Parser())
@@ -507,9 +510,9 @@ func TestWatchFileSymlink(t *testing.T) {
 	changedC := make(chan string, 1)
 	var wg sync.WaitGroup
 	wg.Add(1) // our assurance that cb is called max once
-	f.Watch(func(event any, err error) {
-		// The File watcher always returns a nil `event`, which can
-		// be ignored.
+	f.Watch(context.Background(), func(event koanf.Event, err error) {
+		// The event carries the path of the watched file, which
+		// can be ignored.
 		if err != nil {
 			// TODO: make use of Error Wrapping-Scheme and assert.ErrorIs() checks as of go v1.13
 			assert.Condition(func() bool {
@@ -589,9 +592,9 @@ func TestWatchFileDirectorySymlink(t *testing.T) {
 	changedC := make(chan string, 1)
 	var wg sync.WaitGroup
 	wg.Add(1) // our assurance that cb is called max once
-	f.Watch(func(event any, err error) {
-		// The File watcher always returns a nil `event`, which can
-		// be ignored.
+	f.Watch(context.Background(), func(event koanf.Event, err error) {
+		// The event carries the path of the watched file, which
+		// can be ignored.
 		if err != nil {
 			// TODO: make use of Error Wrapping-Scheme and assert.ErrorIs() checks as of go v1.13
 			assert.Condition(func() bool {
@@ -637,7 +640,7 @@ func TestUnwatchFile(t *testing.T) {
 
 	// Watch.
 	var reloaded int32
-	f.Watch(func(event any, err error) {
+	f.Watch(context.Background(), func(event koanf.Event, err error) {
 		atomic.StoreInt32(&reloaded, 1)
 		assert.NoError(err)
 	})
@@ -658,7 +661,7 @@ func TestUnwatchFile(t *testing.T) {
 
 	// Re-watch and check again.
 	atomic.StoreInt32(&reloaded, 0)
-	f.Watch(func(event any, err error) {
+	f.Watch(context.Background(), func(event koanf.Event, err error) {
 		atomic.StoreInt32(&reloaded, 1)
 		assert.NoError(err)
 	})
@@ -669,6 +672,52 @@ func TestUnwatchFile(t *testing.T) {
 	f.Unwatch()
 }
 
+func TestWatchFileContext(t *testing.T) {
+	var (
+		assert = assert.New(t)
+		k      = koanf.New(delim)
+	)
+
+	// Create a tmp config file.
+	tmpDir := t.TempDir()
+	tmpFile := filepath.Join(tmpDir, "koanf_mock")
+	require.NoError(t, os.WriteFile(tmpFile, []byte(`{"parent": {"name": "name1"}}`), 0600))
+
+	f := file.Provider(tmpFile)
+	require.NoError(t, k.Load(f, json.Parser()))
+
+	// The file provider should be usable through the generic interface.
+	var w koanf.Watcher = f
+
+	ctx, cancel := context.WithCancel(context.Background())
+	evC := make(chan koanf.Event, 10)
+	require.NoError(t, w.Watch(ctx, func(ev koanf.Event, err error) {
+		assert.NoError(err)
+		evC <- ev
+	}))
+
+	// A second watch on the same provider is an error.
+	assert.Error(w.Watch(context.Background(), func(ev koanf.Event, err error) {}))
+
+	time.Sleep(100 * time.Millisecond)
+	require.NoError(t, os.WriteFile(tmpFile, []byte(`{"parent": {"name": "name2"}}`), 0600))
+
+	select {
+	case ev := <-evC:
+		assert.Equal(tmpFile, ev.Key)
+		assert.NotNil(ev.Raw)
+	case <-time.After(5 * time.Second):
+		assert.Fail("timeout waiting for file watch trigger")
+	}
+
+	// Cancelling the context should stop the watch and allow re-watching.
+	cancel()
+	assert.Eventually(func() bool {
+		return w.Watch(context.Background(), func(ev koanf.Event, err error) {}) == nil
+	}, time.Second, 10*time.Millisecond)
+	assert.NoError(w.Unwatch())
+}
+
 func TestLoadMerge(t *testing.T) {
 	var (
 		assert = assert.New(t)
@@ -1096,6 +1145,51 @@ func TestMergeAt(t *testing.T) {
 	assert.Equal(expected.Get(""), reversed.Get(""), "conf map mismatch")
 }
 
+func TestDiff(t *testing.T) {
+	var (
+		assert = assert.New(t)
+		k      = koanf.New(delim)
+	)
+	assert.Nil(k.Load(file.Provider(mockYAML), yaml.Parser()), "error loading file")
+
+	// No changes.
+	n := k.Copy()
+	assert.True(k.Diff(n).IsEmpty())
+
+	n.Set("parent1.name", "changed")
+	n.Set("parent1.new", true)
+	n.Delete("parent2.child2")
+
+	ch := k.Diff(n)
+	assert.Equal([]kmaps.Change{{Key: "parent1.new", New: true}}, ch.Added)
+	assert.Equal([]kmaps.Change{{Key: "parent1.name", Old: "parent1", New: "changed"}}, ch.Changed)
+	assert.Equal([]string{
+		"parent2.child2.empty",
+		"parent2.child2.grandchild2.ids",
+		"parent2.child2.grandchild2.on",
+		"parent2.child2.name",
+	}, keysOf(ch.Removed))
+	assert.Equal("child2", ch.Removed[3].Old)
+
+	// The reverse diff swaps added and removed.
+	rev := n.Diff(k)
+	assert.Equal(keysOf(ch.Removed), keysOf(rev.Added))
+	assert.Equal(keysOf(ch.Added), keysOf(rev.Removed))
+
+	// Changes are copies and don't modify the config.
+	ids := ch.Removed[1].Old.([]any)
+	ids[0] = 100
+	assert.Equal([]int64{4, 5, 6}, k.Int64s("parent2.child2.grandchild2.ids"))
+}
+
+func keysOf(ch []kmaps.Change) []string {
+	out := make([]string, 0, len(ch))
+	for _, c := range ch {
+		out = append(out, c.Key)
+	}
+	return out
+}
+
 func TestSet(t *testing.T) {
 	var (
 		assert = assert.New(t)
@@ -1582,7 +1676,7 @@ func TestFileWatcherRaceCondition(t *testing.T) {
 	// Setup file watcher that reloads the SAME koanf instance
 	// This tests our internal thread safety, not user pattern issues
 	var reloadCount int64
-	provider.Watch(func(event any, err error) {
+	provider.Watch(context.Background(), func(event koanf.Event, err error) {
 		if err != nil {
 			t.Logf("watch error: %v", err)
 			return
@@ -2014,7 +2108,7 @@ func TestFileProviderConcurrency(t *testing.T) {
 
 					// Try to watch
 					var watchErr error
-					watchErr = f.Watch(func(event any, err error) {
+					watchErr = f.Watch(context.Background(), func(event koanf.Event, err error) {
 						// Simple callback that doesn't do much
 						_ = event
 						_ = err
@@ -2108,3 +2202,472 @@ func TestGetNilPointer(t *testing.T) {
 	_, ok = gotSlice.(*[]string)
 	assert.True(ok, "expected type *[]string, got %T", gotSlice)
 }
+
+func TestLoadWithSliceMerge(t *testing.T) {
+	k := koanf.New(delim)
+	require.NoError(t, k.Load(confmap.Provider(map[string]any{
+		"plugins":     []any{"auth"},
+		"backends":    []any{map[string]any{"name": "a", "weight": 1}},
+		"allowed_ips": []any{"10.0.0.1"},
+	}, delim), nil))
+
+	require.NoError(t, k.Load(confmap.Provider(map[string]any{
+		"plugins":     []any{"cache"},
+		"backends":    []any{map[string]any{"name": "a", "weight": 5}, map[string]any{"name": "b"}},
+		"allowed_ips": []any{"10.0.0.2"},
+	}, delim), nil, koanf.WithSliceMerge(kmaps.SliceAppend,
+		kmaps.SliceRule{Pattern: "backends", Merge: kmaps.SliceMergeByField("name")},
+		kmaps.SliceRule{Pattern: "allowed_ips", Merge: kmaps.SliceReplace},
+	)))
+
+	assert.Equal(t, []string{"auth", "cache"}, k.Strings("plugins"))
+	assert.Equal(t, []string{"10.0.0.2"}, k.Strings("allowed_ips"))
+	assert.Equal(t, []any{
+		map[string]any{"name": "a", "weight": 5},
+		map[string]any{"name": "b"},
+	}, k.Get("backends"))
+
+	// As a merge function.
+	require.NoError(t, k.Load(confmap.Provider(map[string]any{"plugins": []any{"auth", "log"}}, delim), nil,
+		koanf.WithMergeFunc(kmaps.SliceMerger(delim, kmaps.SliceUnion))))
+	assert.Equal(t, []string{"auth", "cache", "log"}, k.Strings("plugins"))
+}
+
+func TestQuotedKeys(t *testing.T) {
+	assert := assert.New(t)
+
+	k := koanf.New(delim)
+	require.NoError(t, k.Load(rawbytes.Provider([]byte(`{
+		"hosts": {"api.example.com": {"port": 443}, "localhost": {"port": 80}},
+		"labels": {"app.kubernetes.io/name": "web"}
+	}`)), json.Parser()))
+
+	assert.Equal([]string{`hosts."api.example.com".port`, "hosts.localhost.port", `labels."app.kubernetes.io/name"`}, k.Keys())
+	assert.Equal(443, k.Int(`hosts."api.example.com".port`))
+	assert.Equal(80, k.Int(`hosts."localhost".port`), "unnecessary quotes")
+	assert.Equal("web", k.String(`labels."app.kubernetes.io/name"`))
+	assert.True(k.Exists(`hosts."api.example.com"`))
+	assert.False(k.Exists("hosts.api"))
+	assert.False(k.Exists("hosts.api.example.com.port"))
+	assert.Equal([]string{"api.example.com", "localhost"}, k.MapKeys("hosts"))
+	assert.Equal(443, k.Cut(`hosts."api.example.com"`).Int("port"))
+	assert.Equal(443, k.Cut("hosts").Int(`"api.example.com".port`))
+	assert.Equal(443, k.View("hosts").Get(`"api.example.com".port`).Int())
+
+	require.NoError(t, k.Set(`hosts."api.example.com".port`, 8443))
+	require.NoError(t, k.Set(`hosts."db.example.com".port`, 5432))
+	assert.Equal(8443, k.Int(`hosts."api.example.com".port`))
+	assert.Equal(map[string]any{"port": 5432}, k.Get(`hosts."db.example.com"`))
+
+	n := koanf.New(delim)
+	require.NoError(t, n.Set("tls", true))
+	require.NoError(t, k.MergeAt(n, `hosts."api.example.com"`))
+	assert.True(k.Bool(`hosts."api.example.com".tls`))
+
+	k.Delete(`hosts."api.example.com"`)
+	assert.False(k.Exists(`hosts."api.example.com".port`))
+	assert.True(k.Exists(`hosts."db.example.com".port`))
+
+	b, err := k.Marshal(json.Parser())
+	require.NoError(t, err)
+	assert.JSONEq(`{
+		"hosts": {"db.example.com": {"port": 5432}, "localhost": {"port": 80}},
+		"labels": {"app.kubernetes.io/name": "web"}
+	}`, string(b))
+}
+
+func TestFlatProviderKeys(t *testing.T) {
+	assert := assert.New(t)
+
+	// Providers like etcd and Consul return flat keys that contain
+	// the delimiter without nesting them.
+	k := koanf.New(delim)
+	require.NoError(t, k.Load(confmap.Provider(map[string]any{
+		"parent1.name":  "p",
+		"parent1.child": map[string]any{"Value": "v"},
+		"db":            map[string]any{"api.host": "h"},
+	}, ""), nil))
+
+	assert.Equal("p", k.String("parent1.name"))
+	assert.Equal("p", k.String(`"parent1.name"`))
+	assert.True(k.Exists("parent1.name"))
+	assert.Equal("v", k.String("parent1.child.Value"))
+	assert.False(k.Exists("parent1"))
+	assert.Equal([]string{`"parent1.child".Value`, `"parent1.name"`, `db."api.host"`}, k.Keys())
+
+	// Only top-level keys are looked up without quotes.
+	assert.False(k.Exists("db.api.host"))
+	assert.Equal("h", k.String(`db."api.host"`))
+
+	// Setting them doesn't add a nested key.
+	require.NoError(t, k.Set("parent1.name", "q"))
+	assert.Equal("q", k.String(`"parent1.name"`))
+	assert.Equal(map[string]any{"Value": "v"}, k.Raw()["parent1.child"])
+	assert.Nil(k.Raw()["parent1"])
+
+	k.Delete("parent1.name")
+	assert.False(k.Exists(`"parent1.name"`))
+
+	k = koanf.NewWithConf(koanf.Conf{Delim: delim, CaseInsensitive: true})
+	require.NoError(t, k.Load(confmap.Provider(map[string]any{"Parent1.Name": "p"}, ""), nil))
+	assert.Equal("p", k.String("parent1.name"))
+}
+
+func TestIndexedKeys(t *testing.T) {
+	assert := assert.New(t)
+
+	k := koanf.New(delim)
+	require.NoError(t, k.Load(rawbytes.Provider([]byte(`{
+		"servers": [
+			{"host": "a", "port": 80, "tags": ["x", "y"]},
+			{"host": "b", "port": 81}
+		],
+		"brokers": ["k1", "k2", "k3"],
+		"matrix": [[1, 2], [3, 4]]
+	}`)), json.Parser()))
+
+	assert.Equal("a", k.String("servers.0.host"))
+	assert.Equal("b", k.String("servers[1].host"))
+	assert.Equal(81, k.Int("servers.1.port"))
+	assert.Equal("y", k.String("servers[0].tags[1]"))
+	assert.Equal("k2", k.String("brokers.1"))
+	assert.Equal(4, k.Int("matrix[1][1]"))
+	assert.Equal(map[string]any{"host": "b", "port": float64(81)}, k.Get("servers.1"))
+	assert.True(k.Exists("servers.0.tags"))
+	assert.False(k.Exists("servers.2"))
+	assert.False(k.Exists("servers.-1"))
+	assert.False(k.Exists("servers.x"))
+	assert.False(k.Exists("brokers.0.x"))
+	assert.Equal("a", k.Cut("servers.0").String("host"))
+	assert.Equal("b", k.View("servers[1]").Get("host").String())
+	assert.Equal("y", k.View("servers").Get("0.tags[1]").String())
+	assert.Equal(80, koanf.MustGet[int](k, "servers.0.port"))
+
+	// Indexed keys are not flattened.
+	assert.Equal([]string{"brokers", "matrix", "servers"}, k.Keys())
+
+	// Set elements.
+	require.NoError(t, k.Set("servers.0.host", "a2"))
+	require.NoError(t, k.Set("servers[1].tls", true))
+	require.NoError(t, k.Set("brokers.2", "k3b"))
+	require.NoError(t, k.Set("brokers.3", "k4"))
+	require.NoError(t, k.Set("matrix.0.1", 5))
+	assert.Equal("a2", k.String("servers.0.host"))
+	assert.Equal([]string{"x", "y"}, k.Strings("servers.0.tags"))
+	assert.True(k.Bool("servers.1.tls"))
+	assert.Equal("b", k.String("servers.1.host"))
+	assert.Equal([]string{"k1", "k2", "k3b", "k4"}, k.Strings("brokers"))
+	assert.Equal(5, k.Int("matrix.0.1"))
+
+	err := k.Set("brokers.9", "x")
+	assert.ErrorContains(err, "brokers.9")
+	assert.Error(k.Set("brokers.x", "x"))
+
+	// Delete elements.
+	k.Delete("brokers[0]")
+	assert.Equal([]string{"k2", "k3b", "k4"}, k.Strings("brokers"))
+	k.Delete("servers.1.tls")
+	assert.False(k.Exists("servers.1.tls"))
+	k.Delete("servers.0")
+	assert.Equal("b", k.String("servers.0.host"))
+	assert.False(k.Exists("servers.1"))
+	k.Delete("servers.5")
+	assert.Equal(1, len(k.Slices("servers")))
+}
+
+func TestIndexedKeysInterpolate(t *testing.T) {
+	k := koanf.NewWithConf(koanf.Conf{Delim: delim, Interpolate: true, CaseInsensitive: true})
+	require.NoError(t, k.Load(confmap.Provider(map[string]any{
+		"Servers": []any{map[string]any{"Host": "a"}},
+		"url":     "http://${Servers.0.Host}",
+	}, delim), nil))
+
+	assert.Equal(t, "http://a", k.String("url"))
+	assert.Equal(t, "a", k.String("SERVERS[0].HOST"))
+
+	require.NoError(t, k.Set("servers.0.host", "b"))
+	assert.Equal(t, "http://b", k.String("url"))
+	assert.Equal(t, map[string]any{"Host": "b"}, k.Get("servers.0"))
+}
+
+func TestQuery(t *testing.T) {
+	assert := assert.New(t)
+
+	k := koanf.New("/")
+	require.NoError(t, k.Load(confmap.Provider(map[string]any{
+		"services/api/port":         8080,
+		"services/api/timeout":      "10s",
+		"services/web/port":         80,
+		"services/web/http/timeout": "30s",
+		"db/timeout":                "5s",
+		"timeout":                   "1m",
+	}, "/"), nil))
+
+	assert.Equal(map[string]any{
+		"services/api/port": 8080,
+		"services/web/port": 80,
+	}, k.Query("services/*/port"))
+
+	assert.Equal(map[string]any{
+		"services/api/timeout":      "10s",
+		"services/web/http/timeout": "30s",
+		"db/timeout":                "5s",
+		"timeout":                   "1m",
+	}, k.Query("**/timeout"))
+
+	assert.Equal(map[string]any{
+		"db/timeout":        "5s",
+		"services/api/port": 8080,
+	}, k.Query("db/*", "services/api/p*"))
+
+	// Maps match too.
+	assert.Equal(map[string]any{
+		"services/web/http": map[string]any{"timeout": "30s"},
+	}, k.Query("services/*/http"))
+
+	assert.Empty(k.Query("nope/*"))
+	assert.Empty(k.Query())
+
+	// All timeouts must be under 30s.
+	for key, v := range k.Query("**/timeout") {
+		d, err := time.ParseDuration(v.(string))
+		require.NoError(t, err)
+		if key == "timeout" || key == "services/web/http/timeout" {
+			assert.GreaterOrEqual(d, 30*time.Second, key)
+		} else {
+			assert.Less(d, 30*time.Second, key)
+		}
+	}
+
+	c := koanf.NewWithConf(koanf.Conf{Delim: delim, CaseInsensitive: true})
+	require.NoError(t, c.Load(confmap.Provider(map[string]any{"Services.API.Port": 1}, delim), nil))
+	assert.Equal(map[string]any{"Services.API.Port": 1}, c.Query("services.*.port"))
+}
+
+func TestEval(t *testing.T) {
+	assert := assert.New(t)
+
+	k := koanf.NewWithConf(koanf.Conf{Delim: delim, Evaluator: jmespath.Evaluator()})
+	require.NoError(t, k.Load(rawbytes.Provider([]byte(`
+upstreams:
+  - host: a
+    weight: 10
+  - host: b
+    weight: 2
+  - host: c
+    weight: 7
+db:
+  host: localhost
+  port: 5432
+`)), yaml.Parser()))
+
+	v, err := k.Eval("upstreams[?weight > `5`].host")
+	require.NoError(t, err)
+	assert.Equal([]string{"a", "c"}, v.Strings())
+
+	v, err = k.Eval("max_by(upstreams, &weight)")
+	require.NoError(t, err)
+	assert.Equal("a", v.Get("host").String())
+	assert.Equal(10, v.Get("weight").Int())
+
+	v, err = k.Eval("length(upstreams)")
+	require.NoError(t, err)
+	assert.Equal(3, v.Int())
+
+	// Map results as instances.
+	v, err = k.Eval("db")
+	require.NoError(t, err)
+	db := v.Koanf()
+	assert.Equal("localhost", db.String("host"))
+	assert.Equal(5432, db.Int("port"))
+	require.NoError(t, db.Set("host", "remote"))
+	assert.Equal("localhost", k.String("db.host"))
+
+	v, err = k.Eval("nope")
+	require.NoError(t, err)
+	assert.False(v.Exists())
+	assert.Empty(v.Koanf().Keys())
+
+	_, err = k.Eval("upstreams[?")
+	assert.ErrorContains(err, "upstreams[?")
+
+	_, err = koanf.New(delim).Eval("db")
+	assert.Error(err)
+}
+
+func TestEdit(t *testing.T) {
+	for _, c := range []struct {
+		path   string
+		parser koanf.Parser
+	}{
+		{mockJSON, json.Parser()},
+		{mockYAML, yaml.Parser()},
+		{mockTOML, toml.Parser()},
+		{mockHJSON, hjson.Parser()},
+	} {
+		t.Run(c.path, func(t *testing.T) {
+			b, err := os.ReadFile(c.path)
+			require.NoError(t, err)
+
+			k := koanf.New(delim)
+			require.NoError(t, k.Load(rawbytes.Provider(b), c.parser))
+
+			// Unchanged documents are written back as they are.
+			if _, ok := c.parser.(koanf.Editor); ok {
+				out, err := k.Edit(c.parser, b)
+				require.NoError(t, err)
+				assert.Equal(t, string(b), string(out))
+			}
+
+			require.NoError(t, k.Set("parent1.name", "edited"))
+			require.NoError(t, k.Set("parent1.new.key", "value"))
+			k.Delete("parent1.child1")
+
+			out, err := k.Edit(c.parser, b)
+			require.NoError(t, err)
+
+			n := koanf.New(delim)
+			require.NoError(t, n.Load(rawbytes.Provider(out), c.parser))
+			assert.Equal(t, k.All(), n.All())
+		})
+	}
+
+	// Comments are preserved.
+	b := []byte("# Database.\ndb:\n  # The host.\n  host: localhost # Not remote.\n  port: 5432\n")
+	k := koanf.New(delim)
+	require.NoError(t, k.Load(rawbytes.Provider(b), yaml.Parser()))
+	require.NoError(t, k.Set("db.host", "remote"))
+	k.Delete("db.port")
+
+	out, err := k.Edit(yaml.Parser(), b)
+	require.NoError(t, err)
+	assert.Equal(t, "# Database.\ndb:\n  # The host.\n  host: remote # Not remote.\n", string(out))
+
+	// References are written unresolved.
+	b = []byte("host: localhost\nurl: http://${host}/\n")
+	k = koanf.NewWithConf(koanf.Conf{Delim: delim, Interpolate: true})
+	require.NoError(t, k.Load(rawbytes.Provider(b), yaml.Parser()))
+	require.NoError(t, k.Set("host", "remote"))
+	assert.Equal(t, "http://remote/", k.String("url"))
+
+	out, err = k.Edit(yaml.Parser(), b)
+	require.NoError(t, err)
+	assert.Equal(t, "host: remote\nurl: http://${host}/\n", string(out))
+
+	// Resolved secrets and keys loaded from other sources aren't written.
+	t.Setenv("APP_DB.PORT", "5432")
+	b = []byte("db:\n  host: localhost\n  password: vault://secret/db#password\n")
+	k = koanf.NewWithConf(koanf.Conf{
+		Delim: delim,
+		Resolvers: map[string]koanf.Resolver{
+			"vault": mockVault(map[string]any{"secret/db": map[string]any{"password": "s3cret"}}),
+		},
+	})
+	require.NoError(t, k.Load(rawbytes.Provider(b), yaml.Parser()))
+	require.NoError(t, k.Load(env.Provider(".", env.Opt{
+		Prefix: "APP_",
+		TransformFunc: func(k, v string) (string, any) {
+			return strings.ToLower(strings.TrimPrefix(k, "APP_")), v
+		},
+	}), nil))
+	require.NoError(t, k.Set("db.host", "remote"))
+	assert.Equal(t, "s3cret", k.String("db.password"))
+	assert.Equal(t, "5432", k.String("db.port"))
+
+	out, err = k.Edit(yaml.Parser(), b)
+	require.NoError(t, err)
+	assert.Equal(t, "db:\n  host: remote\n  password: vault://secret/db#password\n", string(out))
+	assert.NotContains(t, string(out), "s3cret")
+	assert.NotContains(t, string(out), "port")
+
+	// Without a document, only the changes are written.
+	out, err = k.Edit(json.Parser(), nil)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"db": {"host": "remote"}}`, string(out))
+}
+
+func TestSave(t *testing.T) {
+	var (
+		dir  = t.TempDir()
+		path = filepath.Join(dir, "conf.yml")
+		src  = "# Database.\ndb:\n  host: localhost # The host.\n"
+	)
+	require.NoError(t, os.WriteFile(path, []byte(src), 0o600))
+
+	// Saving through a symlink replaces its target.
+	link := filepath.Join(dir, "link.yml")
+	require.NoError(t, os.Symlink(path, link))
+
+	p := file.Provider(link)
+	k := koanf.New(delim)
+	require.NoError(t, k.Load(p, yaml.Parser()))
+	require.NoError(t, k.Set("db.host", "remote"))
+	require.NoError(t, k.Set("db.port", 5432))
+	require.NoError(t, k.Save(p, yaml.Parser()))
+
+	b, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "# Database.\ndb:\n  host: remote # The host.\n  port: 5432\n", string(b))
+
+	st, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), st.Mode().Perm())
+
+	st, err = os.Lstat(link)
+	require.NoError(t, err)
+	assert.Equal(t, os.ModeSymlink, st.Mode()&os.ModeSymlink)
+
+	// No temporary files are left behind.
+	files, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Len(t, files, 2)
+
+	// New files are created.
+	newPath := filepath.Join(dir, "new.json")
+	require.NoError(t, k.Save(file.Provider(newPath), json.Parser()))
+	n := koanf.New(delim)
+	require.NoError(t, n.Load(file.Provider(newPath), json.Parser()))
+	assert.Equal(t, "remote", n.String("db.host"))
+	assert.Equal(t, 5432, n.Int("db.port"))
+
+	// The file provider can only write bytes.
+	assert.Error(t, k.Save(p, nil))
+	assert.Error(t, k.Save(nil, json.Parser()))
+
+	// Sources that can't be read aren't overwritten with only the changes.
+	ms := &memSource{err: os.ErrPermission}
+	assert.ErrorIs(t, k.Save(ms, json.Parser()), os.ErrPermission)
+	assert.ErrorIs(t, k.Save(ms, nil), os.ErrPermission)
+	assert.False(t, ms.written)
+
+	// Sources that don't exist are written with the changes.
+	ms = &memSource{err: os.ErrNotExist}
+	require.NoError(t, k.Save(ms, nil))
+	assert.Equal(t, map[string]any{"db": map[string]any{"host": "remote", "port": 5432}}, ms.mp)
+}
+
+// memSource is a Provider and Writer of a config map in memory,
+// whose reads fail with err if it's set.
+type memSource struct {
+	mp      map[string]any
+	err     error
+	written bool
+}
+
+func (m *memSource) ReadBytes() ([]byte, error) {
+	return nil, m.err
+}
+
+func (m *memSource) Read() (map[string]any, error) {
+	return m.mp, m.err
+}
+
+func (m *memSource) WriteBytes([]byte) error {
+	m.written = true
+	return nil
+}
+
+func (m *memSource) Write(mp map[string]any) error {
+	m.mp, m.written = mp, true
+	return nil
+}
@@ -0,0 +1,193 @@
+package koanf_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/knadh/koanf/parsers/json"
+	"github.com/knadh/koanf/providers/confmap"
+	"github.com/knadh/koanf/providers/env/v2"
+	"github.com/knadh/koanf/providers/file"
+	"github.com/knadh/koanf/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLayers(t *testing.T) {
+	assert := assert.New(t)
+
+	tmpFile := filepath.Join(t.TempDir(), "conf.json")
+	require.NoError(t, os.WriteFile(tmpFile, []byte(`{"db": {"host": "file-host", "port": 5432, "user": "file-user"}}`), 0600))
+	t.Setenv("LAYERS_DB.USER", "env-user")
+
+	k := koanf.New(delim)
+	l := koanf.NewLayers(k)
+	assert.Equal(k, l.Koanf())
+
+	require.NoError(t, l.Add("defaults", confmap.Provider(map[string]any{
+		"db.host": "localhost",
+		"db.name": "app",
+	}, delim), nil))
+	require.NoError(t, l.Add("file", file.Provider(tmpFile), json.Parser()))
+	require.NoError(t, l.Add("env", env.Provider(delim, env.Opt{Prefix: "LAYERS_", TransformFunc: func(k, v string) (string, any) {
+		return strings.ToLower(k[len("LAYERS_"):]), v
+	}}), nil))
+
+	assert.Error(l.Add("file", file.Provider(tmpFile), json.Parser()), "duplicate layer name")
+	assert.Equal([]string{"defaults", "file", "env"}, l.Names())
+
+	assert.Equal("file-host", k.String("db.host"))
+	assert.Equal(5432, k.Int("db.port"))
+	assert.Equal("app", k.String("db.name"))
+	assert.Equal("env-user", k.String("db.user"))
+
+	// Drop keys from the file and reload only the file layer. Keys removed
+	// from the file should disappear or fall back to the lower layers.
+	require.NoError(t, os.WriteFile(tmpFile, []byte(`{"db": {"user": "file-user"}}`), 0600))
+	require.NoError(t, l.Reload("file"))
+
+	assert.Equal("localhost", k.String("db.host"))
+	assert.False(k.Exists("db.port"))
+	assert.Equal("env-user", k.String("db.user"))
+	assert.Equal(map[string]any{"db": map[string]any{"user": "file-user"}}, l.Raw("file"))
+	assert.Nil(l.Raw("nope"))
+
+	// Replace a layer directly.
+	require.NoError(t, l.Replace("defaults", map[string]any{"db": map[string]any{"name": "other"}}))
+	assert.False(k.Exists("db.host"))
+	assert.Equal("other", k.String("db.name"))
+
+	// Remove a layer.
+	require.NoError(t, l.Remove("env"))
+	assert.Equal("file-user", k.String("db.user"))
+	assert.Equal([]string{"defaults", "file"}, l.Names())
+
+	assert.Error(l.Reload("env"))
+	assert.Error(l.Replace("env", nil))
+	assert.Error(l.Remove("env"))
+
+	// Reading errors retain the previous state.
+	require.NoError(t, os.WriteFile(tmpFile, []byte(`{bad json`), 0600))
+	assert.Error(l.Reload("file"))
+	assert.Equal("file-user", k.String("db.user"))
+}
+
+func TestLayersStrictMergeRollback(t *testing.T) {
+	assert := assert.New(t)
+
+	k := koanf.NewWithConf(koanf.Conf{Delim: delim, StrictMerge: true})
+	l := koanf.NewLayers(k)
+
+	require.NoError(t, l.Add("a", confmap.Provider(map[string]any{"port": 1}, delim), nil))
+	require.NoError(t, l.Add("b", confmap.Provider(map[string]any{"port": 2}, delim), nil))
+	assert.Equal(2, k.Int("port"))
+
+	// A type conflict in a replaced layer is rejected and the old config retained.
+	assert.Error(l.Replace("b", map[string]any{"port": "two"}))
+	assert.Equal(2, k.Int("port"))
+	assert.Equal(map[string]any{"port": 2}, l.Raw("b"))
+
+	// A conflicting new layer is not added.
+	assert.Error(l.Add("c", confmap.Provider(map[string]any{"port": "three"}, delim), nil))
+	assert.Equal([]string{"a", "b"}, l.Names())
+}
+
+func TestLayersProvenance(t *testing.T) {
+	k := koanf.NewWithConf(koanf.Conf{Delim: delim, Provenance: true})
+	l := koanf.NewLayers(k)
+
+	require.NoError(t, l.Add("defaults", confmap.Provider(map[string]any{"name": "a"}, delim), nil))
+	require.NoError(t, l.Add("override", confmap.Provider(map[string]any{"name": "b"}, delim), nil))
+
+	e := k.Explain("name")
+	require.Len(t, e.Origins, 2)
+	assert.Equal(t, "defaults", e.Origins[0].Source)
+	assert.Equal(t, "override", e.Origins[1].Source)
+	assert.Equal(t, 1, e.Winner)
+}
+
+func TestLayersResolveOnce(t *testing.T) {
+	var (
+		calls int
+		deps  []koanf.Deprecation
+	)
+	k := koanf.NewWithConf(koanf.Conf{
+		Delim:   delim,
+		Aliases: map[string]string{"db.addr": "database.host"},
+		OnDeprecated: func(d koanf.Deprecation) {
+			deps = append(deps, d)
+		},
+		Resolvers: map[string]koanf.Resolver{
+			"vault": koanf.ResolverFunc(func(string) (any, error) {
+				calls++
+				return "s3cret", nil
+			}),
+		},
+	})
+	l := koanf.NewLayers(k)
+
+	// Layers are resolved and their deprecations reported once when they are read.
+	require.NoError(t, l.Add("file", confmap.Provider(map[string]any{
+		"db.addr":     "localhost",
+		"db.password": "vault://db#password",
+	}, delim), nil))
+	require.NoError(t, l.Add("env", confmap.Provider(map[string]any{"port": 1}, delim), nil))
+	require.NoError(t, l.Add("flags", confmap.Provider(map[string]any{"port": 2}, delim), nil))
+	require.NoError(t, l.Reload("env"))
+
+	assert.Equal(t, 1, calls)
+	assert.Equal(t, []koanf.Deprecation{{Key: "db.addr", NewKey: "database.host"}}, deps)
+	assert.Equal(t, "localhost", k.String("database.host"))
+	assert.Equal(t, "s3cret", k.String("db.password"))
+	assert.True(t, k.IsSensitive("db.password"))
+	assert.Equal(t, "vault://db#password", l.Raw("file")["db"].(map[string]any)["password"])
+
+	// Replacing a layer resolves it again.
+	deps = nil
+	require.NoError(t, l.Replace("file", map[string]any{"db": map[string]any{"addr": "remote", "password": "vault://db#password"}}))
+	assert.Equal(t, 2, calls)
+	assert.Len(t, deps, 1)
+	assert.Equal(t, "remote", k.String("database.host"))
+}
+
+func TestLayersWatch(t *testing.T) {
+	assert := assert.New(t)
+
+	tmpFile := filepath.Join(t.TempDir(), "conf.json")
+	require.NoError(t, os.WriteFile(tmpFile, []byte(`{"a": 1, "b": 2}`), 0600))
+
+	k := koanf.New(delim)
+	l := koanf.NewLayers(k)
+	require.NoError(t, l.Add("defaults", confmap.Provider(map[string]any{"b": 0}, delim), nil))
+	require.NoError(t, l.Add("file", file.Provider(tmpFile), json.Parser()))
+
+	// Non-watchable providers and unknown layers can't be watched.
+	assert.Error(l.Watch(context.Background(), "defaults", nil))
+	assert.Error(l.Watch(context.Background(), "nope", nil))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	reloaded := make(chan error, 10)
+	require.NoError(t, l.Watch(ctx, "file", func(err error) {
+		reloaded <- err
+	}))
+
+	time.Sleep(100 * time.Millisecond)
+	tmpNext := tmpFile + ".next"
+	require.NoError(t, os.WriteFile(tmpNext, []byte(`{"a": 2}`), 0600))
+	require.NoError(t, os.Rename(tmpNext, tmpFile))
+
+	select {
+	case err := <-reloaded:
+		require.NoError(t, err)
+		assert.Equal(2, k.Int("a"))
+		assert.Equal(0, k.Int("b"))
+	case <-time.After(10 * time.Second):
+		assert.Fail("timeout waiting for layer reload")
+	}
+}
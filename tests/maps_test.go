@@ -103,19 +103,37 @@ var testMap3 = map[string]any{
 func TestFlatten(t *testing.T) {
 	f, k := maps.Flatten(testMap, nil, delim)
 	assert.Equal(t, map[string]any{
-		"parent.child.key":          123,
-		"parent.child.key.with.dot": 456,
-		"top":                       789,
-		"empty":                     map[string]any{},
+		"parent.child.key":            123,
+		`parent.child."key.with.dot"`: 456,
+		"top":                         789,
+		"empty":                       map[string]any{},
 	}, f)
 	assert.Equal(t, map[string][]string{
-		"parent.child.key":          {"parent", "child", "key"},
-		"parent.child.key.with.dot": {"parent", "child", "key.with.dot"},
-		"top":                       {"top"},
-		"empty":                     {"empty"},
+		"parent.child.key":            {"parent", "child", "key"},
+		`parent.child."key.with.dot"`: {"parent", "child", "key.with.dot"},
+		"top":                         {"top"},
+		"empty":                       {"empty"},
 	}, k)
 }
 
+func TestFlattenStrings(t *testing.T) {
+	f, err := maps.FlattenStrings(map[string]any{
+		"app":   map[string]any{"port": 80, "debug": true, "ratio": 0.5},
+		"a/b":   "c",
+		"empty": map[string]any{},
+	}, "/")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"app/port":  "80",
+		"app/debug": "true",
+		"app/ratio": "0.5",
+		"a/b":       "c",
+	}, f)
+
+	_, err = maps.FlattenStrings(map[string]any{"tags": []any{"a"}}, delim)
+	assert.ErrorContains(t, err, "tags")
+}
+
 func BenchmarkFlatten(b *testing.B) {
 	for n := 0; n < b.N; n++ {
 		maps.Flatten(testMap3, nil, delim)
@@ -125,7 +143,7 @@ func BenchmarkFlatten(b *testing.B) {
 func TestUnflatten(t *testing.T) {
 	m, _ := maps.Flatten(testMap, nil, delim)
 	um := maps.Unflatten(m, delim)
-	assert.NotEqual(t, um, testMap)
+	assert.Equal(t, um, testMap)
 
 	m, _ = maps.Flatten(testMap2, nil, delim)
 	um = maps.Unflatten(m, delim)
@@ -389,6 +407,51 @@ func TestCopy(t *testing.T) {
 	assert.Equal(t, mp, maps.Copy(mp))
 }
 
+func TestMapDiff(t *testing.T) {
+	a := map[string]any{
+		"parent": map[string]any{
+			"a":    1,
+			"b":    2,
+			"list": []any{1, 2},
+		},
+		"top":   789,
+		"empty": map[string]any{},
+	}
+	b := map[string]any{
+		"parent": map[string]any{
+			"b":    3,
+			"c":    4,
+			"list": []any{1, 2},
+		},
+		"top": 789,
+		"new": map[string]any{"key": "val"},
+	}
+
+	ch := maps.Diff(a, b, ".")
+	assert.Equal(t, []maps.Change{
+		{Key: "new.key", New: "val"},
+		{Key: "parent.c", New: 4},
+	}, ch.Added)
+	assert.Equal(t, []maps.Change{
+		{Key: "empty", Old: map[string]any{}},
+		{Key: "parent.a", Old: 1},
+	}, ch.Removed)
+	assert.Equal(t, []maps.Change{
+		{Key: "parent.b", Old: 2, New: 3},
+	}, ch.Changed)
+	assert.Equal(t, []string{"empty", "new.key", "parent.a", "parent.b", "parent.c"}, ch.Keys())
+	assert.False(t, ch.IsEmpty())
+
+	// Identical maps.
+	assert.True(t, maps.Diff(a, a, ".").IsEmpty())
+	assert.Empty(t, maps.Diff(nil, nil, ".").Keys())
+
+	// A map replaced by a scalar.
+	ch = maps.Diff(map[string]any{"a": map[string]any{"b": 1}}, map[string]any{"a": 1}, "/")
+	assert.Equal(t, []maps.Change{{Key: "a", New: 1}}, ch.Added)
+	assert.Equal(t, []maps.Change{{Key: "a/b", Old: 1}}, ch.Removed)
+}
+
 func TestLookupMaps(t *testing.T) {
 	assert.Equal(t, map[string]bool{"a": true, "b": true}, maps.StringSliceToLookupMap([]string{"a", "b"}))
 	assert.Equal(t, map[string]bool{}, maps.StringSliceToLookupMap(nil))
@@ -396,3 +459,137 @@ func TestLookupMaps(t *testing.T) {
 	assert.Equal(t, map[int64]bool{}, maps.Int64SliceToLookupMap(nil))
 
 }
+
+func TestSliceMergeFuncs(t *testing.T) {
+	dest := []any{"a", "b"}
+	src := []any{"b", "c"}
+
+	assert.Equal(t, []any{"b", "c"}, maps.SliceReplace(src, dest))
+	assert.Equal(t, []any{"a", "b", "b", "c"}, maps.SliceAppend(src, dest))
+	assert.Equal(t, []any{"b", "c", "a", "b"}, maps.SlicePrepend(src, dest))
+	assert.Equal(t, []any{"a", "b", "c"}, maps.SliceUnion(src, []any{"a", "b", "a"}))
+	assert.Equal(t, []any{"a", "b"}, dest, "dest is not modified")
+
+	assert.Equal(t, []any{
+		map[string]any{"name": "a", "port": 2, "host": "x"},
+		"y",
+		3,
+	}, maps.SliceMergeByIndex(
+		[]any{map[string]any{"port": 2}, "y", 3},
+		[]any{map[string]any{"name": "a", "port": 1, "host": "x"}, map[string]any{"name": "b"}},
+	))
+
+	assert.Equal(t, []any{
+		map[string]any{"name": "a", "port": 1},
+		map[string]any{"name": "b", "port": 3, "on": true},
+		"plain",
+		map[string]any{"name": "c"},
+	}, maps.SliceMergeByField("name")(
+		[]any{map[string]any{"name": "b", "port": 3}, "plain", map[string]any{"name": "c"}},
+		[]any{map[string]any{"name": "a", "port": 1}, map[string]any{"name": "b", "port": 2, "on": true}},
+	))
+}
+
+func TestMergeSlices(t *testing.T) {
+	dest := map[string]any{
+		"plugins": []any{"auth"},
+		"tags":    []any{"a"},
+		"servers": map[string]any{
+			"web": map[string]any{"hosts": []any{"h1"}},
+		},
+		"scalar": []any{1},
+	}
+	src := map[string]any{
+		"plugins": []any{"cache"},
+		"tags":    []any{"a", "b"},
+		"servers": map[string]any{
+			"web": map[string]any{"hosts": []any{"h2"}},
+		},
+		"scalar": 2,
+		"new":    []any{"x"},
+	}
+
+	maps.MergeSlices(src, dest, ".", maps.SliceAppend,
+		maps.SliceRule{Pattern: "servers.*.hosts", Merge: maps.SlicePrepend},
+		maps.SliceRule{Pattern: "tags", Merge: maps.SliceUnion},
+	)
+	assert.Equal(t, map[string]any{
+		"plugins": []any{"auth", "cache"},
+		"tags":    []any{"a", "b"},
+		"servers": map[string]any{
+			"web": map[string]any{"hosts": []any{"h2", "h1"}},
+		},
+		"scalar": 2,
+		"new":    []any{"x"},
+	}, dest)
+
+	// Without a default, slices not matching a rule are replaced.
+	dest = map[string]any{"a": []any{1}, "b": []any{1}}
+	assert.NoError(t, maps.SliceMerger(".", nil, maps.SliceRule{Pattern: "a", Merge: maps.SliceAppend})(
+		map[string]any{"a": []any{2}, "b": []any{2}}, dest))
+	assert.Equal(t, map[string]any{"a": []any{1, 2}, "b": []any{2}}, dest)
+}
+
+func TestMatchKey(t *testing.T) {
+	for _, c := range []struct {
+		pattern, key string
+		match        bool
+	}{
+		{"a.b", "a.b", true},
+		{"a.*", "a.b", true},
+		{"a.*", "a.b.c", false},
+		{"a.**", "a.b.c", true},
+		{"**", "a", true},
+		{"**.c", "a.b.c", true},
+		{"**.c", "c", true},
+		{"*.*_token", "api.auth_token", true},
+		{"a.b", "a", false},
+		{`hosts.*.port`, `hosts."api.example.com".port`, true},
+		{`hosts."api.example.com"`, `hosts."api.example.com"`, true},
+	} {
+		assert.Equal(t, c.match, maps.MatchKey(c.pattern, c.key, "."), c.pattern+" "+c.key)
+	}
+}
+
+func TestSplitJoinKey(t *testing.T) {
+	for _, c := range []struct {
+		key   string
+		parts []string
+	}{
+		{"a.b.c", []string{"a", "b", "c"}},
+		{`hosts."api.example.com".port`, []string{"hosts", "api.example.com", "port"}},
+		{`labels."app.kubernetes.io/name"`, []string{"labels", "app.kubernetes.io/name"}},
+		{`"a.b"`, []string{"a.b"}},
+		{`a."b\"c.d"`, []string{"a", `b"c.d`}},
+		{`a."b\\.c"`, []string{"a", `b\.c`}},
+		{`"\"q"`, []string{`"q`}},
+	} {
+		assert.Equal(t, c.parts, maps.SplitKey(c.key, "."), c.key)
+		assert.Equal(t, c.key, maps.JoinKey(c.parts, "."), c.key)
+	}
+
+	// Quotes that don't enclose a whole part are literal.
+	assert.Equal(t, []string{`a"b`, "c"}, maps.SplitKey(`a"b.c`, "."))
+	assert.Equal(t, []string{`"a`, "b"}, maps.SplitKey(`"a.b`, "."))
+	assert.Equal(t, []string{`"a"b`, "c"}, maps.SplitKey(`"a"b.c`, "."))
+
+	// Unnecessary quotes.
+	assert.Equal(t, []string{"a", "b"}, maps.SplitKey(`"a"."b"`, "."))
+
+	// Other delimiters.
+	assert.Equal(t, []string{"a", "b/c"}, maps.SplitKey(`a/"b/c"`, "/"))
+	assert.Equal(t, []string{"a.b"}, maps.SplitKey("a.b", ""))
+}
+
+func TestAnnotation(t *testing.T) {
+	a := maps.NewAnnotation([]string{"b.y", "b.x", "a"}, map[string]string{"b.x": "X.\nSecond line.", "a": ""}, ".")
+	mp := map[string]any{"a": 1, "b": map[string]any{"x": 1, "y": 2}, "c": 3}
+
+	// Parents are ordered by their first key and the unknown keys are last.
+	assert.Equal(t, []string{"b", "a", "c"}, a.Keys(mp, nil))
+	assert.Equal(t, []string{"y", "x"}, a.Keys(mp["b"].(map[string]any), []string{"b"}))
+
+	assert.Equal(t, "  # X.\n  # Second line.\n", a.Comment([]string{"b", "x"}, "  "))
+	assert.Equal(t, "", a.Comment([]string{"a"}, ""))
+	assert.Equal(t, "", a.Comment([]string{"c"}, ""))
+}
@@ -0,0 +1,168 @@
+package koanf_test
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/knadh/koanf/parsers/yaml"
+	"github.com/knadh/koanf/providers/confmap"
+	"github.com/knadh/koanf/providers/file"
+	"github.com/knadh/koanf/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOnChange(t *testing.T) {
+	assert := assert.New(t)
+
+	k := koanf.New(delim)
+	require.NoError(t, k.Load(file.Provider(mockYAML), yaml.Parser()))
+
+	var (
+		p1Calls, p2Calls, allCalls int
+		lastOld, lastNew           *koanf.Koanf
+	)
+	k.OnChange("parent1", func(old, new *koanf.Koanf) {
+		p1Calls++
+		lastOld, lastNew = old, new
+
+		// Callbacks can safely use the instance.
+		_ = k.String("parent1.name")
+	})
+	unsub := k.OnChange("parent2", func(old, new *koanf.Koanf) {
+		p2Calls++
+	})
+	k.OnChange("", func(old, new *koanf.Koanf) {
+		allCalls++
+	})
+
+	// Set a key under parent1.
+	require.NoError(t, k.Set("parent1.name", "changed"))
+	assert.Equal(1, p1Calls)
+	assert.Equal(0, p2Calls)
+	assert.Equal(1, allCalls)
+	assert.Equal("parent1", lastOld.String("parent1.name"))
+	assert.Equal("changed", lastNew.String("parent1.name"))
+
+	// The instances passed to callbacks are copies.
+	lastNew.Set("parent1.name", "modified")
+	assert.Equal("changed", k.String("parent1.name"))
+
+	// Setting the same value is not a change.
+	require.NoError(t, k.Set("parent1.name", "changed"))
+	assert.Equal(1, p1Calls)
+	assert.Equal(1, allCalls)
+
+	// A key that shares the prefix string but not the path doesn't match.
+	require.NoError(t, k.Set("parent1x", 1))
+	assert.Equal(1, p1Calls)
+	assert.Equal(2, allCalls)
+
+	// Delete and Merge.
+	k.Delete("parent2.child2")
+	assert.Equal(1, p2Calls)
+
+	n := koanf.New(delim)
+	require.NoError(t, n.Load(confmap.Provider(map[string]any{"parent2.id": 1}, delim), nil))
+	require.NoError(t, k.Merge(n))
+	assert.Equal(2, p2Calls)
+
+	// Deleting a non-existent key doesn't notify.
+	k.Delete("does.not.exist")
+	assert.Equal(2, p2Calls)
+
+	// Unsubscribed callbacks are not called.
+	unsub()
+	unsub()
+	k.Delete("parent2")
+	assert.Equal(2, p2Calls)
+	assert.Equal(5, allCalls)
+
+	// Reloading a layer notifies.
+	l := koanf.NewLayers(k)
+	require.NoError(t, l.Add("a", confmap.Provider(map[string]any{"parent1.name": "layer"}, delim), nil))
+	assert.Equal(2, p1Calls)
+	assert.Equal("layer", lastNew.String("parent1.name"))
+	assert.False(lastNew.Exists("type"))
+}
+
+func TestOnChangeConcurrent(t *testing.T) {
+	k := koanf.New(delim)
+
+	var (
+		wg    sync.WaitGroup
+		calls int64
+	)
+	for i := 0; i < 10; i++ {
+		wg.Add(2)
+		go func(i int) {
+			defer wg.Done()
+			k.OnChange(fmt.Sprintf("key%d", i), func(old, new *koanf.Koanf) {
+				atomic.AddInt64(&calls, 1)
+			})
+		}(i)
+		go func(i int) {
+			defer wg.Done()
+			_ = k.Set(fmt.Sprintf("other%d", i), i)
+			_ = k.String("other0")
+		}(i)
+	}
+	wg.Wait()
+
+	for i := 0; i < 10; i++ {
+		require.NoError(t, k.Set(fmt.Sprintf("key%d", i), i))
+	}
+	assert.Equal(t, int64(10), atomic.LoadInt64(&calls))
+}
+
+func TestOnChangeOrder(t *testing.T) {
+	k := koanf.New(delim)
+	require.NoError(t, k.Set("n", 0))
+
+	// Notifications of concurrent changes are delivered one at a time, in
+	// the order of the changes: each old config is the previous new config.
+	var (
+		seen     [][2]int
+		inflight int64
+		next     int64
+		wg       sync.WaitGroup
+	)
+	k.OnChange("n", func(old, new *koanf.Koanf) {
+		assert.Equal(t, int64(1), atomic.AddInt64(&inflight, 1), "callbacks are invoked one at a time")
+		seen = append(seen, [2]int{old.Int("n"), new.Int("n")})
+		atomic.AddInt64(&inflight, -1)
+	})
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 20; j++ {
+				_ = k.Set("n", int(atomic.AddInt64(&next, 1)))
+			}
+		}()
+	}
+	wg.Wait()
+
+	require.Len(t, seen, 400)
+	assert.Equal(t, 0, seen[0][0])
+	for i := 1; i < len(seen); i++ {
+		assert.Equal(t, seen[i-1][1], seen[i][0])
+	}
+	assert.Equal(t, k.Int("n"), seen[len(seen)-1][1])
+
+	// Changes made by callbacks are delivered after the callback returns.
+	var events []string
+	k.OnChange("a", func(old, new *koanf.Koanf) {
+		events = append(events, "a start")
+		require.NoError(t, k.Set("b", new.Int("a")))
+		events = append(events, "a end")
+	})
+	k.OnChange("b", func(old, new *koanf.Koanf) {
+		events = append(events, "b")
+	})
+	require.NoError(t, k.Set("a", 1))
+	assert.Equal(t, []string{"a start", "a end", "b"}, events)
+	assert.Equal(t, 1, k.Int("b"))
+}
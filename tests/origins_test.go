@@ -0,0 +1,92 @@
+package koanf_test
+
+import (
+	"testing"
+
+	"github.com/knadh/koanf/parsers/json"
+	"github.com/knadh/koanf/parsers/yaml"
+	"github.com/knadh/koanf/providers/confmap"
+	"github.com/knadh/koanf/providers/file"
+	"github.com/knadh/koanf/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExplain(t *testing.T) {
+	assert := assert.New(t)
+
+	k := koanf.NewWithConf(koanf.Conf{Delim: delim, Provenance: true})
+	require.NoError(t, k.Load(confmap.Provider(map[string]any{
+		"type":                "defaults",
+		"parent1.child1.name": "default-name",
+		"extra":               "x",
+	}, delim), nil, koanf.WithSource("defaults")))
+	require.NoError(t, k.Load(file.Provider(mockJSON), json.Parser()))
+	require.NoError(t, k.Load(file.Provider(mockYAML), yaml.Parser()))
+
+	// Three layers contributed to "type" and the last one won.
+	e := k.Explain("type")
+	assert.Equal("type", e.Key)
+	assert.Equal("yml", e.Value)
+	require.Len(t, e.Origins, 3)
+	assert.Equal(2, e.Winner)
+
+	assert.Equal("defaults", e.Origins[0].Source)
+	assert.Equal("*confmap.Confmap", e.Origins[0].Provider)
+	assert.Equal("", e.Origins[0].Parser)
+	assert.Equal(1, e.Origins[0].Order)
+	assert.Equal("defaults", e.Origins[0].Value)
+
+	assert.Equal("*file.File", e.Origins[1].Source)
+	assert.Equal("*json.JSON", e.Origins[1].Parser)
+	assert.Equal(2, e.Origins[1].Order)
+	assert.Equal("json", e.Origins[1].Value)
+
+	assert.Equal("*yaml.YAML", e.Origins[2].Parser)
+	assert.Equal(3, e.Origins[2].Order)
+
+	// Only the defaults contributed "extra".
+	e = k.Explain("extra")
+	require.Len(t, e.Origins, 1)
+	assert.Equal(0, e.Winner)
+	assert.Equal("defaults", e.Origins[0].Source)
+
+	// Set is recorded as well.
+	require.NoError(t, k.Set("type", "set"))
+	e = k.Explain("type")
+	require.Len(t, e.Origins, 4)
+	assert.Equal(3, e.Winner)
+	assert.Equal("Set", e.Origins[3].Provider)
+	assert.Equal(4, e.Origins[3].Order)
+
+	// Non-existent keys have no origins.
+	e = k.Explain("does.not.exist")
+	assert.Empty(e.Origins)
+	assert.Equal(-1, e.Winner)
+
+	// Deleted keys lose their provenance.
+	k.Delete("extra")
+	assert.Empty(k.Explain("extra").Origins)
+
+	// Overwriting a map with a scalar drops the provenance of its children.
+	require.NoError(t, k.Set("parent1.child1", "flat"))
+	assert.Empty(k.Explain("parent1.child1.name").Origins)
+	assert.Len(k.Explain("parent1.child1").Origins, 1)
+
+	// Cut carries over the provenance with trimmed keys.
+	c := k.Cut("parent2")
+	e = c.Explain("child2.name")
+	require.Len(t, e.Origins, 2)
+	assert.Equal("*json.JSON", e.Origins[0].Parser)
+	assert.Equal(1, e.Winner)
+}
+
+func TestExplainDisabled(t *testing.T) {
+	k := koanf.New(delim)
+	require.NoError(t, k.Load(file.Provider(mockJSON), json.Parser()))
+
+	e := k.Explain("type")
+	assert.Equal(t, "json", e.Value)
+	assert.Empty(t, e.Origins)
+	assert.Equal(t, -1, e.Winner)
+}
@@ -0,0 +1,145 @@
+package koanf_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/knadh/koanf/parsers/dotenv"
+	"github.com/knadh/koanf/parsers/hcl"
+	"github.com/knadh/koanf/parsers/hjson"
+	"github.com/knadh/koanf/parsers/json"
+	"github.com/knadh/koanf/parsers/toml"
+	"github.com/knadh/koanf/parsers/yaml"
+	"github.com/knadh/koanf/providers/rawbytes"
+	"github.com/knadh/koanf/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type sampleServer struct {
+	Host string `koanf:"host" default:"localhost" desc:"Host to listen on."`
+	Port int    `koanf:"port" default:"8080"`
+}
+
+type sampleConf struct {
+	Name    string            `koanf:"name" default:"app" desc:"Name of the app."`
+	Debug   bool              `koanf:"debug" desc:"Enable debug logs."`
+	Timeout time.Duration     `koanf:"timeout" default:"30s" desc:"Request timeout."`
+	Tags    []string          `koanf:"tags" default:"a,b"`
+	DB      sampleDB          `koanf:"db" desc:"Database.\nOnly postgres is supported."`
+	Servers []sampleServer    `koanf:"servers" desc:"Servers."`
+	Labels  map[string]string `koanf:"labels"`
+	Ignored func()            `koanf:"ignored"`
+}
+
+type sampleDB struct {
+	DSN      string   `koanf:"dsn" desc:"Connection string."`
+	MaxConns int      `koanf:"max_conns" default:"10"`
+	Hosts    []string `koanf:"hosts"`
+}
+
+func TestSample(t *testing.T) {
+	out, err := koanf.Sample(sampleConf{}, delim, yaml.Parser(), koanf.UnmarshalConf{})
+	require.NoError(t, err)
+	assert.Equal(t, `# Name of the app.
+name: app
+# Enable debug logs.
+debug: false
+# Request timeout.
+timeout: 30s
+tags:
+    - a
+    - b
+# Database.
+# Only postgres is supported.
+db:
+    # Connection string.
+    dsn: ""
+    max_conns: 10
+    hosts: []
+# Servers.
+servers:
+    - # Host to listen on.
+      host: localhost
+      port: 8080
+labels: {}
+`, string(out))
+
+	out, err = koanf.Sample(&sampleConf{}, delim, dotenv.Parser(), koanf.UnmarshalConf{})
+	require.NoError(t, err)
+	assert.Equal(t, `# Name of the app.
+name="app"
+# Enable debug logs.
+debug="false"
+# Request timeout.
+timeout="30s"
+# tags="a,b"
+# Database.
+# Only postgres is supported.
+# Connection string.
+db.dsn=""
+db.max_conns=10
+# db.hosts=""
+# Servers.
+# Host to listen on.
+# servers.0.host="localhost"
+# servers.0.port=8080
+`, string(out))
+
+	// The generated configs unmarshal to the defaults.
+	var def sampleConf
+	require.NoError(t, koanf.New(delim).UnmarshalWithConf("", &def, koanf.UnmarshalConf{Defaults: true}))
+
+	// .env files have no slices, which are commented out in the sample.
+	k := koanf.New(delim)
+	require.NoError(t, k.Load(rawbytes.Provider(out), dotenv.ParserEnv("", delim, nil)))
+	var env sampleConf
+	require.NoError(t, k.Unmarshal("", &env))
+	assert.Equal(t, sampleConf{Name: def.Name, Timeout: def.Timeout, DB: sampleDB{MaxConns: def.DB.MaxConns}}, env)
+
+	for name, p := range map[string]koanf.Parser{
+		"yaml":  yaml.Parser(),
+		"toml":  toml.Parser(),
+		"json":  json.Parser(),
+		"hcl":   hcl.Parser(true),
+		"hjson": hjson.Parser(),
+	} {
+		t.Run(name, func(t *testing.T) {
+			b, err := koanf.Sample(sampleConf{}, delim, p, koanf.UnmarshalConf{})
+			require.NoError(t, err)
+
+			k := koanf.New(delim)
+			require.NoError(t, k.Load(rawbytes.Provider(b), p))
+
+			var c sampleConf
+			require.NoError(t, k.Unmarshal("", &c))
+			assert.Equal(t, def.Name, c.Name)
+			assert.Equal(t, def.Timeout, c.Timeout)
+			assert.Equal(t, def.Tags, c.Tags)
+			assert.Equal(t, def.DB.MaxConns, c.DB.MaxConns)
+			assert.Equal(t, []sampleServer{{Host: "localhost", Port: 8080}}, c.Servers)
+		})
+	}
+
+	// Flat paths.
+	var flat struct {
+		Host string `koanf:"db.host" default:"localhost" desc:"Host."`
+		Port int    `koanf:"db.port"`
+		Name string `koanf:"name"`
+	}
+	out, err = koanf.Sample(flat, delim, toml.Parser(), koanf.UnmarshalConf{FlatPaths: true})
+	require.NoError(t, err)
+	assert.Equal(t, "name = ''\n\n[db]\n# Host.\nhost = 'localhost'\nport = 0\n", string(out))
+
+	_, err = koanf.Sample(1, delim, yaml.Parser(), koanf.UnmarshalConf{})
+	assert.Error(t, err)
+
+	_, err = koanf.Sample(sampleConf{}, delim, nil, koanf.UnmarshalConf{})
+	assert.Error(t, err)
+
+	var bad struct {
+		Port int `koanf:"port" default:"http"`
+	}
+	_, err = koanf.Sample(bad, delim, yaml.Parser(), koanf.UnmarshalConf{})
+	assert.ErrorContains(t, err, "invalid default for port")
+}
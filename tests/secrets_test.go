@@ -0,0 +1,315 @@
+package koanf_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/knadh/koanf/evaluators/jmespath"
+	"github.com/knadh/koanf/parsers/json"
+	"github.com/knadh/koanf/parsers/yaml"
+	"github.com/knadh/koanf/providers/confmap"
+	"github.com/knadh/koanf/providers/rawbytes"
+	"github.com/knadh/koanf/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// mockVault resolves vault://path#field references from a map.
+func mockVault(secrets map[string]any) koanf.Resolver {
+	return koanf.ResolverFunc(func(ref string) (any, error) {
+		path, field, _ := strings.Cut(strings.TrimPrefix(ref, "vault://"), "#")
+		mp, ok := secrets[path].(map[string]any)
+		if !ok {
+			return nil, errors.New("secret not found")
+		}
+		if field == "" {
+			return mp, nil
+		}
+		return mp[field], nil
+	})
+}
+
+func TestResolvers(t *testing.T) {
+	assert := assert.New(t)
+
+	k := koanf.NewWithConf(koanf.Conf{
+		Delim: delim,
+		Resolvers: map[string]koanf.Resolver{
+			"vault": mockVault(map[string]any{
+				"secret/db":  map[string]any{"password": "s3cret"},
+				"secret/api": map[string]any{"key": "abc", "id": 1},
+			}),
+		},
+	})
+
+	require.NoError(t, k.Load(rawbytes.Provider([]byte(`
+db:
+  host: localhost
+  password: "vault://secret/db#password"
+api: "vault://secret/api"
+tokens: ["vault://secret/db#password", "plain"]
+site: "https://example.com"
+`)), yaml.Parser()))
+
+	assert.Equal("s3cret", k.String("db.password"))
+	assert.Equal("abc", k.String("api.key"))
+	assert.Equal(1, k.Int("api.id"))
+	assert.Equal([]string{"s3cret", "plain"}, k.Strings("tokens"))
+	assert.Equal("https://example.com", k.String("site"), "unregistered schemes are left as they are")
+
+	assert.Equal([]string{"api.id", "api.key", "db.password", "tokens"}, k.SensitiveKeys())
+	assert.True(k.IsSensitive("db.password"))
+	assert.True(k.IsSensitive("api.key"), "children of sensitive keys are sensitive")
+	assert.False(k.IsSensitive("db.host"))
+	assert.False(k.IsSensitive("db"))
+
+	// Cut carries over sensitive keys.
+	assert.True(k.Cut("db").IsSensitive("password"))
+	assert.True(k.Cut("api").IsSensitive("key"))
+	assert.False(k.Cut("db").IsSensitive("host"))
+
+	// Overwriting a secret with a plain value unmarks it.
+	require.NoError(t, k.Set("db.password", "plain"))
+	assert.False(k.IsSensitive("db.password"))
+
+	k.Delete("api")
+	assert.Equal([]string{"tokens"}, k.SensitiveKeys())
+
+	// Errors name the referencing key and the load is rejected.
+	err := k.Load(confmap.Provider(map[string]any{
+		"db.host":     "remote",
+		"db.password": "vault://secret/missing#password",
+	}, delim), nil)
+	require.Error(t, err)
+	assert.Contains(err.Error(), "db.password")
+	assert.Equal("localhost", k.String("db.host"))
+
+	// Keys that contain the delimiter.
+	k = koanf.NewWithConf(koanf.Conf{
+		Delim:     delim,
+		Resolvers: map[string]koanf.Resolver{"vault": mockVault(map[string]any{"x": map[string]any{"key": "abc"}})},
+	})
+	require.NoError(t, k.Load(rawbytes.Provider([]byte(`{"api.example.com": "vault://x#key", "hosts": {"db.example.com": "vault://x#key"}}`)), json.Parser()))
+	assert.Equal("abc", k.String(`"api.example.com"`))
+	assert.True(k.IsSensitive(`"api.example.com"`))
+	assert.True(k.IsSensitive(`hosts."db.example.com"`))
+	assert.Equal([]string{`"api.example.com"`, `hosts."db.example.com"`}, k.SensitiveKeys())
+}
+
+func TestResolversInterpolate(t *testing.T) {
+	k := koanf.NewWithConf(koanf.Conf{
+		Delim:       delim,
+		Interpolate: true,
+		Resolvers: map[string]koanf.Resolver{
+			"vault": mockVault(map[string]any{
+				"secret/db": map[string]any{"password": "pa${ss}"},
+			}),
+		},
+	})
+
+	require.NoError(t, k.Load(confmap.Provider(map[string]any{
+		"db.user":     "app",
+		"db.password": "vault://secret/db#password",
+		"db.dsn":      "${db.user}:${db.password}@localhost",
+	}, delim), nil))
+
+	// Secret values are not interpolated.
+	assert.Equal(t, "pa${ss}", k.String("db.password"))
+	assert.Equal(t, "app:pa${ss}@localhost", k.String("db.dsn"))
+	assert.True(t, k.IsSensitive("db.password"))
+
+	// Values that reference secrets, directly or through other
+	// references, are sensitive as well.
+	require.NoError(t, k.Load(confmap.Provider(map[string]any{
+		"url":      "postgres://${db.dsn}",
+		"copy":     "${db.password}",
+		"db_copy":  "${db}",
+		"user":     "${db.user}",
+		"fallback": "${nope:-x}",
+	}, delim), nil))
+	assert.Equal(t, []string{
+		"copy", "db.dsn", "db.password", "db_copy.dsn", "db_copy.password", "db_copy.user", "url",
+	}, k.SensitiveKeys())
+	assert.False(t, k.IsSensitive("user"))
+	assert.NotContains(t, k.Sprint(), "pa${ss}")
+
+	// Once the reference is removed, the value isn't sensitive.
+	require.NoError(t, k.Set("copy", "plain"))
+	assert.False(t, k.IsSensitive("copy"))
+	k.Delete("db.password")
+	assert.Equal(t, []string{}, k.SensitiveKeys())
+}
+
+func TestSensitiveInterpolate(t *testing.T) {
+	k := koanf.NewWithConf(koanf.Conf{
+		Delim:       delim,
+		Interpolate: true,
+		Sensitive:   []string{"db.password"},
+	})
+	require.NoError(t, k.Load(confmap.Provider(map[string]any{
+		"db.password": "s3cret",
+		"dsn":         "postgres://u:${db.password}@h",
+	}, delim), nil))
+
+	assert.Equal(t, []string{"db.password", "dsn"}, k.SensitiveKeys())
+	assert.Equal(t, "postgres://u:s3cret@h", k.String("dsn"))
+}
+
+func TestRedactSlices(t *testing.T) {
+	k := koanf.NewWithConf(koanf.Conf{
+		Delim:       delim,
+		Interpolate: true,
+		Sensitive:   []string{"**.password", "keys.1"},
+	})
+	require.NoError(t, k.Load(rawbytes.Provider([]byte(`{
+		"dbs": [{"host": "h", "password": "s3cret"}, {"host": "g"}],
+		"keys": ["public", "private"],
+		"copy": "${dbs}"
+	}`)), json.Parser()))
+
+	assert.True(t, k.IsSensitive("dbs.0.password"))
+	assert.Equal(t, []string{"copy", "dbs.0.password", "keys.1"}, k.SensitiveKeys())
+
+	// The elements of slices are redacted by their index.
+	out := k.Sprint()
+	assert.NotContains(t, out, "s3cret")
+	assert.NotContains(t, out, "private")
+	assert.Contains(t, out, "dbs -> [map[host:h password:******] map[host:g]]")
+	assert.Contains(t, out, "keys -> [public ******]")
+
+	all := k.All()
+	assert.Equal(t, []any{map[string]any{"host": "h", "password": koanf.RedactedValue}, map[string]any{"host": "g"}}, all["dbs"])
+
+	b, err := k.MarshalRedacted(json.Parser())
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"dbs": [{"host": "h", "password": "******"}, {"host": "g"}],
+		"keys": ["public", "******"],
+		"copy": "******"
+	}`, string(b))
+
+	// The config isn't modified.
+	assert.Equal(t, "s3cret", k.String("dbs.0.password"))
+	assert.Equal(t, []string{"public", "private"}, k.Strings("keys"))
+}
+
+// sensitiveProvider is a confmap Provider that provides secrets.
+type sensitiveProvider struct {
+	*confmap.Confmap
+}
+
+func (sensitiveProvider) Sensitive() bool {
+	return true
+}
+
+func TestRedact(t *testing.T) {
+	assert := assert.New(t)
+
+	k := koanf.NewWithConf(koanf.Conf{
+		Delim:     delim,
+		Sensitive: []string{"db.password", "**.*_token", "certs"},
+	})
+	require.NoError(t, k.Load(confmap.Provider(map[string]any{
+		"db.host":            "localhost",
+		"db.password":        "s3cret",
+		"api.auth.api_token": "abc",
+		"refresh_token":      "def",
+		"certs.key":          "pem",
+	}, delim), nil))
+	require.NoError(t, k.Load(confmap.Provider(map[string]any{"smtp.password": "mail"}, delim), nil, koanf.WithSensitive()))
+	require.NoError(t, k.Load(sensitiveProvider{confmap.Provider(map[string]any{"kv.key": "vault"}, delim)}, nil))
+
+	assert.Equal([]string{"api.auth.api_token", "certs.key", "db.password", "kv.key", "refresh_token", "smtp.password"}, k.SensitiveKeys())
+	assert.True(k.IsSensitive("certs.key"))
+	assert.False(k.IsSensitive("db.host"))
+
+	// Getters return the real values.
+	assert.Equal("s3cret", k.String("db.password"))
+	assert.Equal("mail", k.Get("smtp.password"))
+	assert.Equal("vault", k.Raw()["kv"].(map[string]any)["key"])
+
+	// Output helpers redact.
+	assert.Equal(`api.auth.api_token -> ******
+certs.key -> ******
+db.host -> localhost
+db.password -> ******
+kv.key -> ******
+refresh_token -> ******
+smtp.password -> ******
+`, k.Sprint())
+
+	all := k.All()
+	assert.Equal("localhost", all["db.host"])
+	assert.Equal(koanf.RedactedValue, all["db.password"])
+	assert.Equal(koanf.RedactedValue, all["kv.key"])
+
+	b, err := k.MarshalRedacted(json.Parser())
+	require.NoError(t, err)
+	assert.JSONEq(`{
+		"api": {"auth": {"api_token": "******"}},
+		"certs": {"key": "******"},
+		"db": {"host": "localhost", "password": "******"},
+		"kv": {"key": "******"},
+		"refresh_token": "******",
+		"smtp": {"password": "******"}
+	}`, string(b))
+
+	b, err = k.Marshal(json.Parser())
+	require.NoError(t, err)
+	assert.Contains(string(b), "s3cret")
+
+	// Overwriting a value loaded WithSensitive with a plain load unmarks it.
+	require.NoError(t, k.Set("smtp.password", "plain"))
+	assert.False(k.IsSensitive("smtp.password"))
+
+	// Copies retain the sensitive keys.
+	c := k.Cut("db")
+	assert.True(c.IsSensitive("password"))
+	assert.Contains(c.Sprint(), "password -> ******")
+}
+
+func TestRedactViews(t *testing.T) {
+	assert := assert.New(t)
+
+	k := koanf.NewWithConf(koanf.Conf{
+		Delim:     delim,
+		Sensitive: []string{"**.password"},
+		Evaluator: jmespath.Evaluator(),
+	})
+	require.NoError(t, k.Load(confmap.Provider(map[string]any{
+		"db.host":     "localhost",
+		"db.password": "s3cret",
+	}, delim), nil))
+	require.NoError(t, k.Load(confmap.Provider(map[string]any{"api.key": "abc"}, delim), nil, koanf.WithSensitive()))
+
+	// Instances from Views keep the Conf and the sensitive keys.
+	db := k.View("db").Koanf()
+	assert.True(db.IsSensitive("password"))
+	assert.Equal("host -> localhost\npassword -> ******\n", db.Sprint())
+	require.NoError(t, db.Set("user.password", "u"))
+	assert.True(db.IsSensitive("user.password"))
+	assert.True(k.View("").Koanf().IsSensitive("api.key"))
+	assert.True(k.View("api").Koanf().IsSensitive("key"))
+	assert.Equal("key -> ******\n", k.View("").Get("api").Koanf().Sprint())
+
+	// So do instances from Slices.
+	require.NoError(t, k.Load(confmap.Provider(map[string]any{
+		"dbs": []any{
+			map[string]any{"host": "a", "password": "p1"},
+			map[string]any{"host": "b"},
+		},
+	}, delim), nil))
+	sl := k.Slices("dbs")
+	require.Len(t, sl, 2)
+	assert.Equal("host -> a\npassword -> ******\n", sl[0].Sprint())
+	assert.Equal([]string{"password"}, sl[0].SensitiveKeys())
+	require.NoError(t, sl[0].Set("replica.password", "r"))
+	assert.True(sl[0].IsSensitive("replica.password"), "the Conf is kept")
+	assert.Empty(sl[1].SensitiveKeys())
+
+	// The values of sensitive keys in the results of Eval are sensitive.
+	v, err := k.Eval("{host: db.host, pass: db.password, key: api.key}")
+	require.NoError(t, err)
+	assert.Equal("host -> localhost\nkey -> ******\npass -> ******\n", v.Koanf().Sprint())
+}
@@ -0,0 +1,101 @@
+package koanf_test
+
+import (
+	"errors"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/knadh/koanf/providers/confmap"
+	"github.com/knadh/koanf/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSnapshot(t *testing.T) {
+	assert := assert.New(t)
+
+	k := koanf.NewWithConf(koanf.Conf{Delim: delim, Provenance: true})
+	require.NoError(t, k.Load(confmap.Provider(map[string]any{
+		"db.host": "localhost",
+		"db.port": 5432,
+		"tags":    []any{"a"},
+	}, delim), nil))
+
+	s := k.Snapshot()
+
+	// Changes to the instance are not reflected in the snapshot.
+	require.NoError(t, k.Set("db.host", "remote"))
+	require.NoError(t, k.Set("db.user", "app"))
+	k.Delete("tags")
+
+	assert.Equal("localhost", s.String("db.host"))
+	assert.Equal(5432, s.Int("db.port"))
+	assert.False(s.Exists("db.user"))
+	assert.Equal([]string{"a"}, s.Strings("tags"))
+	assert.Equal([]string{"db.host", "db.port", "tags"}, s.Keys())
+	assert.Len(s.Explain("db.host").Origins, 1)
+	assert.Equal("remote", k.String("db.host"))
+	assert.Len(k.Explain("db.host").Origins, 2)
+
+	// Snapshots can't be changed.
+	assert.True(errors.Is(s.Set("db.host", "x"), koanf.ErrReadOnly))
+	assert.True(errors.Is(s.Load(confmap.Provider(map[string]any{"a": 1}, delim), nil), koanf.ErrReadOnly))
+	assert.True(errors.Is(s.Merge(k), koanf.ErrReadOnly))
+	s.Delete("db")
+	assert.Equal("localhost", s.String("db.host"))
+	err := koanf.NewLayers(s).Add("a", confmap.Provider(map[string]any{"a": 1}, delim), nil)
+	assert.True(errors.Is(err, koanf.ErrReadOnly))
+
+	// Values returned by getters are copies.
+	s.Get("tags").([]any)[0] = "x"
+	s.Get("db").(map[string]any)["host"] = "x"
+	assert.Equal([]string{"a"}, s.Strings("tags"))
+	assert.Equal("localhost", s.String("db.host"))
+
+	// Copies of snapshots are writable.
+	c := s.Copy()
+	require.NoError(t, c.Set("db.host", "copy"))
+	assert.Equal("copy", c.String("db.host"))
+	assert.Equal("localhost", s.String("db.host"))
+
+	// OnChange callbacks receive snapshots.
+	k.OnChange("", func(old, new *koanf.Koanf) {
+		assert.True(errors.Is(old.Set("a", 1), koanf.ErrReadOnly))
+		assert.Equal("remote", old.String("db.host"))
+		assert.Equal("changed", new.String("db.host"))
+	})
+	require.NoError(t, k.Set("db.host", "changed"))
+}
+
+func TestConcurrentReads(t *testing.T) {
+	k := koanf.New(delim)
+	require.NoError(t, k.Load(confmap.Provider(map[string]any{"a.b": 0, "a.c": 0}, delim), nil))
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 1; i <= 200; i++ {
+			// Both keys are changed in a single load.
+			_ = k.Load(confmap.Provider(map[string]any{"a.b": i, "a.c": strconv.Itoa(i)}, delim), nil)
+		}
+	}()
+
+	for n := 0; n < 4; n++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 200; i++ {
+				s := k.Snapshot()
+				assert.Equal(t, strconv.Itoa(s.Int("a.b")), s.String("a.c"))
+				_ = k.All()
+				_ = k.Keys()
+				_ = k.Sprint()
+			}
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, 200, k.Int("a.b"))
+}
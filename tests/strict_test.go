@@ -0,0 +1,193 @@
+package koanf_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/knadh/koanf/parsers/yaml"
+	"github.com/knadh/koanf/providers/confmap"
+	"github.com/knadh/koanf/providers/rawbytes"
+	"github.com/knadh/koanf/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type strictBackend struct {
+	Host string `koanf:"host"`
+	Port int    `koanf:"port"`
+}
+
+type strictBase struct {
+	Env string `koanf:"env"`
+}
+
+type strictConf struct {
+	strictBase `koanf:",squash"`
+
+	Name     string                    `koanf:"name"`
+	Database strictBackend             `koanf:"database"`
+	Cache    *strictBackend            `koanf:"cache"`
+	Servers  []strictBackend           `koanf:"servers"`
+	Named    map[string]*strictBackend `koanf:"named"`
+	Labels   map[string]string         `koanf:"labels"`
+	Extra    any                       `koanf:"extra"`
+	Skip     string                    `koanf:"-"`
+}
+
+func TestUnmarshalStrict(t *testing.T) {
+	k := koanf.New(delim)
+	require.NoError(t, k.Load(rawbytes.Provider([]byte(`
+name: app
+env: prod
+NAMe2: x
+databse:
+  host: typo
+database:
+  host: db
+  hsot: typo
+  nested:
+    x: 1
+servers:
+  - host: a
+    port: 1
+  - host: b
+    prot: 2
+named:
+  x:
+    host: x
+labels:
+  a: b
+extra:
+  anything: 1
+`)), yaml.Parser()))
+
+	var c strictConf
+
+	// Without Strict, the extra keys are ignored.
+	require.NoError(t, k.Unmarshal("", &c))
+
+	c = strictConf{}
+	err := k.UnmarshalWithConf("", &c, koanf.UnmarshalConf{Strict: true})
+	var serr *koanf.StrictError
+	require.True(t, errors.As(err, &serr))
+	unused := []string{
+		"NAMe2",
+		"database.hsot",
+		"database.nested",
+		"databse",
+		"servers.1.prot",
+	}
+	assert.Equal(t, unused, serr.Unused)
+
+	// Unset fields are only reported with StrictUnset.
+	assert.Empty(t, serr.Unset)
+	assert.NotContains(t, err.Error(), "unset fields")
+
+	err = k.UnmarshalWithConf("", &c, koanf.UnmarshalConf{Strict: true, StrictUnset: true})
+	require.True(t, errors.As(err, &serr))
+	assert.Equal(t, unused, serr.Unused)
+	assert.Equal(t, []string{
+		"database.port",
+		"cache.host",
+		"cache.port",
+		"servers.1.port",
+		"named.x.port",
+	}, serr.Unset)
+	assert.Contains(t, err.Error(), "unused keys: NAMe2, database.hsot")
+	assert.Contains(t, err.Error(), "unset fields: database.port, cache.host")
+
+	// The struct is unmarshalled regardless.
+	assert.Equal(t, "db", c.Database.Host)
+	assert.Equal(t, "prod", c.Env)
+
+	// Unmarshalling a path.
+	var b strictBackend
+	err = k.UnmarshalWithConf("database", &b, koanf.UnmarshalConf{Strict: true, StrictUnset: true})
+	require.True(t, errors.As(err, &serr))
+	assert.Equal(t, []string{"database.hsot", "database.nested"}, serr.Unused)
+	assert.Equal(t, []string{"database.port"}, serr.Unset)
+
+	// StrictUnset without Strict only reports the unset fields.
+	err = k.UnmarshalWithConf("database", &b, koanf.UnmarshalConf{StrictUnset: true})
+	require.True(t, errors.As(err, &serr))
+	assert.Empty(t, serr.Unused)
+	assert.Equal(t, []string{"database.port"}, serr.Unset)
+
+	// Fields with defaults aren't unset.
+	var d struct {
+		Host string `koanf:"host"`
+		Port int    `koanf:"port" default:"80"`
+	}
+	require.NoError(t, k.UnmarshalWithConf("servers.0", &d, koanf.UnmarshalConf{Strict: true, StrictUnset: true}))
+	require.Error(t, k.UnmarshalWithConf("named.x", &d, koanf.UnmarshalConf{StrictUnset: true}))
+	require.NoError(t, k.UnmarshalWithConf("named.x", &d, koanf.UnmarshalConf{StrictUnset: true, Defaults: true}))
+	assert.Equal(t, 80, d.Port)
+}
+
+func TestUnmarshalStrictRemain(t *testing.T) {
+	k := koanf.New(delim)
+	require.NoError(t, k.Load(confmap.Provider(map[string]any{
+		"name":  "app",
+		"other": 1,
+	}, delim), nil))
+
+	var c struct {
+		Name string         `koanf:"name"`
+		Rest map[string]any `koanf:",remain"`
+	}
+	require.NoError(t, k.UnmarshalWithConf("", &c, koanf.UnmarshalConf{Strict: true}))
+	assert.Equal(t, map[string]any{"other": 1}, c.Rest)
+}
+
+func TestUnmarshalStrictFlat(t *testing.T) {
+	k := koanf.New(delim)
+	require.NoError(t, k.Load(confmap.Provider(map[string]any{
+		"db.host": "db",
+		"db.hsot": "typo",
+	}, delim), nil))
+
+	var c struct {
+		Host string `koanf:"db.host"`
+		Port int    `koanf:"db.port"`
+	}
+	err := k.UnmarshalWithConf("", &c, koanf.UnmarshalConf{Strict: true, StrictUnset: true, FlatPaths: true})
+	var serr *koanf.StrictError
+	require.True(t, errors.As(err, &serr))
+	assert.Equal(t, []string{"db.hsot"}, serr.Unused)
+	assert.Equal(t, []string{"db.port"}, serr.Unset)
+}
+
+func TestUnmarshalStrictValidate(t *testing.T) {
+	k := koanf.New(delim)
+	require.NoError(t, k.Load(confmap.Provider(map[string]any{
+		"host": "db",
+		"prot": 80,
+	}, delim), nil))
+
+	var c struct {
+		Host string `koanf:"host" validate:"min=3"`
+		Port int    `koanf:"port" validate:"required"`
+	}
+
+	// Both checks run and both errors are returned.
+	err := k.UnmarshalWithConf("", &c, koanf.UnmarshalConf{Strict: true, Validate: true})
+	var (
+		serr *koanf.StrictError
+		verr *koanf.ValidationError
+	)
+	require.True(t, errors.As(err, &serr))
+	assert.Equal(t, []string{"prot"}, serr.Unused)
+	assert.Empty(t, serr.Unset)
+	require.True(t, errors.As(err, &verr))
+	assert.Len(t, verr.Violations, 2)
+	assert.Equal(t, "db", c.Host)
+
+	// A single failing check returns its error as it is.
+	require.NoError(t, k.Set("host", "localhost"))
+	require.NoError(t, k.Set("port", 80))
+	err = k.UnmarshalWithConf("", &c, koanf.UnmarshalConf{Strict: true, Validate: true})
+	assert.IsType(t, &koanf.StrictError{}, err)
+
+	k.Delete("prot")
+	assert.NoError(t, k.UnmarshalWithConf("", &c, koanf.UnmarshalConf{Strict: true, Validate: true}))
+}
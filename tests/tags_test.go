@@ -0,0 +1,111 @@
+package koanf_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/knadh/koanf/providers/confmap"
+	"github.com/knadh/koanf/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type validBackend struct {
+	Host string `koanf:"host" validate:"required,hostport"`
+}
+
+type validConf struct {
+	Name     string                   `koanf:"name" validate:"required,min=3,regex=^[a-z]{1,}-[a-z,]+$"`
+	Mode     string                   `koanf:"mode" validate:"oneof=debug release"`
+	Port     int                      `koanf:"port" validate:"required,min=1,max=65535"`
+	Ratio    float64                  `koanf:"ratio" validate:"max=1"`
+	Timeout  time.Duration            `koanf:"timeout" validate:"min=1s,max=1m"`
+	Webhook  string                   `koanf:"webhook" validate:"url"`
+	Tags     []string                 `koanf:"tags" validate:"max=2"`
+	Optional string                   `koanf:"optional" validate:"min=5"`
+	Zero     int                      `koanf:"zero" validate:"required"`
+	Missing  string                   `koanf:"missing" validate:"required"`
+	Backends []validBackend           `koanf:"backends"`
+	Named    map[string]*validBackend `koanf:"named"`
+	DB       struct {
+		User string `koanf:"user" validate:"required"`
+	} `koanf:"db"`
+}
+
+func TestUnmarshalValidate(t *testing.T) {
+	k := koanf.New(delim)
+	require.NoError(t, k.Load(confmap.Provider(map[string]any{
+		"name":     "ab",
+		"mode":     "verbose",
+		"port":     70000,
+		"ratio":    1.5,
+		"timeout":  "500ms",
+		"webhook":  "not a url",
+		"tags":     []string{"a", "b", "c"},
+		"zero":     0,
+		"backends": []any{map[string]any{"host": "localhost:80"}, map[string]any{"host": "localhost"}},
+		"named":    map[string]any{"x": map[string]any{"host": ""}},
+	}, delim), nil))
+
+	var c validConf
+
+	// Without Validate, nothing is checked.
+	require.NoError(t, k.Unmarshal("", &c))
+
+	err := k.UnmarshalWithConf("", &c, koanf.UnmarshalConf{Validate: true})
+	var verr *koanf.ValidationError
+	require.True(t, errors.As(err, &verr))
+
+	got := map[string][]string{}
+	for _, v := range verr.Violations {
+		got[v.Key] = append(got[v.Key], v.Rule)
+	}
+	assert.Equal(t, map[string][]string{
+		"name":            {"min=3", "regex=^[a-z]{1,}-[a-z,]+$"},
+		"mode":            {"oneof=debug release"},
+		"port":            {"max=65535"},
+		"ratio":           {"max=1"},
+		"timeout":         {"min=1s"},
+		"webhook":         {"url"},
+		"tags":            {"max=2"},
+		"missing":         {"required"},
+		"backends.1.host": {"hostport"},
+		"named.x.host":    {"hostport"},
+		"db.user":         {"required"},
+	}, got)
+	assert.Contains(t, err.Error(), "port: must be at most 65535")
+	assert.Contains(t, err.Error(), "tags: length must be at most 2")
+	assert.Contains(t, err.Error(), "missing: is required")
+
+	// Valid config.
+	k = koanf.New(delim)
+	require.NoError(t, k.Load(confmap.Provider(map[string]any{
+		"app.name":    "my-app,x",
+		"app.mode":    "debug",
+		"app.port":    8080,
+		"app.timeout": "10s",
+		"app.webhook": "https://example.com/hook",
+		"app.zero":    0,
+		"app.missing": "x",
+		"app.db.user": "root",
+	}, delim), nil))
+
+	c = validConf{}
+	assert.NoError(t, k.UnmarshalWithConf("app", &c, koanf.UnmarshalConf{Validate: true}))
+}
+
+func TestUnmarshalValidateFlat(t *testing.T) {
+	type flatConf struct {
+		User string `koanf:"db.user" check:"required"`
+		Port int    `koanf:"db.port" check:"max=10"`
+	}
+
+	k := koanf.New(delim)
+	require.NoError(t, k.Load(confmap.Provider(map[string]any{"db.port": 11}, delim), nil))
+
+	var c flatConf
+	err := k.UnmarshalWithConf("", &c, koanf.UnmarshalConf{FlatPaths: true, Validate: true, ValidateTag: "check"})
+	require.Error(t, err)
+	assert.Equal(t, "invalid config: db.user: is required; db.port: must be at most 10", err.Error())
+}
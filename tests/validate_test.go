@@ -0,0 +1,121 @@
+package koanf_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/knadh/koanf/parsers/json"
+	"github.com/knadh/koanf/providers/confmap"
+	"github.com/knadh/koanf/providers/file"
+	"github.com/knadh/koanf/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// requirePort is a validator that requires a positive integer at "port".
+var requirePort = koanf.ValidatorFunc(func(mp map[string]any) error {
+	p, ok := mp["port"].(int)
+	if !ok || p <= 0 {
+		return errors.New("port: must be a positive integer")
+	}
+	return nil
+})
+
+func TestConfValidator(t *testing.T) {
+	assert := assert.New(t)
+
+	k := koanf.NewWithConf(koanf.Conf{Delim: delim, Validator: requirePort})
+
+	// An invalid first load is rejected.
+	assert.EqualError(k.Load(confmap.Provider(map[string]any{"host": "localhost"}, delim), nil),
+		"port: must be a positive integer")
+	assert.Empty(k.Keys())
+
+	require.NoError(t, k.Load(confmap.Provider(map[string]any{"host": "localhost", "port": 80}, delim), nil))
+	assert.Equal(80, k.Int("port"))
+
+	// An invalid reload doesn't replace the good config.
+	assert.Error(k.Load(confmap.Provider(map[string]any{"host": "other", "port": -1}, delim), nil))
+	assert.Equal("localhost", k.String("host"))
+	assert.Equal(80, k.Int("port"))
+
+	// Set, Merge are validated too.
+	assert.Error(k.Set("port", "80"))
+	assert.Equal(80, k.Int("port"))
+
+	n := koanf.New(delim)
+	require.NoError(t, n.Set("port", 0))
+	assert.Error(k.Merge(n))
+	assert.Equal(80, k.Int("port"))
+
+	require.NoError(t, k.Set("port", 8080))
+	assert.Equal(8080, k.Int("port"))
+
+	// Cut instances are not validated against the whole config.
+	require.NoError(t, k.Set("db.host", "db"))
+	c := k.Cut("db")
+	assert.NoError(c.Set("user", "x"))
+}
+
+func TestLoadWithValidator(t *testing.T) {
+	assert := assert.New(t)
+
+	var calls int
+	v := koanf.ValidatorFunc(func(mp map[string]any) error {
+		calls++
+		if _, ok := mp["parent1"]; !ok {
+			return errors.New("parent1 is required")
+		}
+		return nil
+	})
+
+	k := koanf.New(delim)
+	require.NoError(t, k.Load(confmap.Provider(map[string]any{"a": 1}, delim), nil))
+	assert.Equal(0, calls)
+
+	// The validator sees the merged result of the load.
+	require.NoError(t, k.Load(file.Provider(mockJSON), json.Parser(), koanf.WithValidator(v)))
+	assert.Equal(1, calls)
+	assert.Equal(1, k.Int("a"))
+
+	k2 := koanf.New(delim)
+	assert.EqualError(k2.Load(confmap.Provider(map[string]any{"a": 1}, delim), nil, koanf.WithValidator(v)),
+		"parent1 is required")
+	assert.Empty(k2.Keys())
+}
+
+func TestStrictMergeValidatorRollback(t *testing.T) {
+	k := koanf.NewWithConf(koanf.Conf{
+		Delim:       delim,
+		StrictMerge: true,
+		Validator:   koanf.ValidatorFunc(func(map[string]any) error { return nil }),
+	})
+	require.NoError(t, k.Load(confmap.Provider(map[string]any{"a": 1, "b": 2}, delim), nil))
+
+	// A strict merge failure leaves the config untouched.
+	assert.Error(t, k.Load(confmap.Provider(map[string]any{"a": 3, "b": "x"}, delim), nil))
+	assert.Equal(t, 1, k.Int("a"))
+	assert.Equal(t, 2, k.Int("b"))
+}
+
+func TestLayersValidator(t *testing.T) {
+	assert := assert.New(t)
+
+	k := koanf.NewWithConf(koanf.Conf{Delim: delim, Validator: requirePort})
+	l := koanf.NewLayers(k)
+
+	// The merged view of all the layers is validated.
+	assert.Error(l.Add("defaults", confmap.Provider(map[string]any{"host": "localhost"}, delim), nil))
+	assert.Empty(l.Names())
+
+	k = koanf.New(delim)
+	l = koanf.NewLayers(k)
+	require.NoError(t, l.Add("defaults", confmap.Provider(map[string]any{"host": "localhost"}, delim), nil))
+	require.NoError(t, l.Add("file", confmap.Provider(map[string]any{"port": 80}, delim), nil, koanf.WithValidator(requirePort)))
+
+	// Replacing a lower layer is validated against the validator of a higher layer.
+	require.NoError(t, l.Replace("defaults", map[string]any{"host": "other"}))
+	assert.Equal("other", k.String("host"))
+	assert.Error(l.Replace("file", map[string]any{"port": "80"}))
+	assert.Equal(80, k.Int("port"))
+}
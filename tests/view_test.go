@@ -0,0 +1,119 @@
+package koanf_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/knadh/koanf/providers/confmap"
+	"github.com/knadh/koanf/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestView(t *testing.T) {
+	assert := assert.New(t)
+
+	k := koanf.New(delim)
+	require.NoError(t, k.Load(confmap.Provider(map[string]any{
+		"db.host":    "localhost",
+		"db.port":    5432,
+		"db.ssl":     "true",
+		"db.timeout": "5s",
+		"db.ratio":   0.5,
+		"servers": []any{
+			map[string]any{"name": "a", "port": 80},
+			map[string]any{"name": "b", "port": 81},
+		},
+		"tags": []any{"x", 1},
+	}, delim), nil))
+
+	db := k.View("db")
+	assert.True(db.Exists())
+	assert.True(db.IsMap())
+	assert.Equal("db", db.Path())
+	assert.Equal(5, db.Len())
+	assert.Equal([]string{"host", "port", "ratio", "ssl", "timeout"}, db.Keys())
+	assert.Equal("localhost", db.Get("host").String())
+	assert.Equal("db.host", db.Get("host").Path())
+	assert.Equal(5432, db.Get("port").Int())
+	assert.Equal(int64(5432), db.Get("port").Int64())
+	assert.Equal(0.5, db.Get("ratio").Float64())
+	assert.True(db.Get("ssl").Bool())
+	assert.Equal(5*time.Second, db.Get("timeout").Duration())
+
+	// Missing nodes.
+	assert.False(db.Get("nope").Exists())
+	assert.False(db.Get("host.nope").Exists())
+	assert.False(k.View("nope").Exists())
+	assert.Equal("", k.View("nope").String())
+	assert.Equal(0, k.View("nope").Get("a").Int())
+	assert.Equal([]string{}, k.View("nope").Keys())
+
+	// Slices.
+	srv := k.View("servers")
+	assert.True(srv.IsSlice())
+	assert.Equal(2, srv.Len())
+	assert.Equal("b", srv.Index(1).Get("name").String())
+	assert.Equal("servers.1.port", srv.Index(1).Get("port").Path())
+	assert.False(srv.Index(2).Exists())
+	assert.False(srv.Index(-1).Exists())
+	assert.Equal([]string{"x", "1"}, k.View("tags").Strings())
+
+	var names []string
+	srv.Range(func(i string, s koanf.View) bool {
+		names = append(names, i+":"+s.Get("name").String())
+		return true
+	})
+	assert.Equal([]string{"0:a", "1:b"}, names)
+
+	var keys []string
+	k.View("").Range(func(key string, _ koanf.View) bool {
+		keys = append(keys, key)
+		return len(keys) < 2
+	})
+	assert.Equal([]string{"db", "servers"}, keys)
+
+	// Values are copies and the config can't be modified through a View.
+	db.Value().(map[string]any)["host"] = "x"
+	srv.Value().([]any)[0] = "x"
+	srv.Index(0).Value().(map[string]any)["name"] = "x"
+	k.View("tags").Strings()[0] = "y"
+	assert.Equal("localhost", k.String("db.host"))
+	assert.Equal("a", k.View("servers").Index(0).Get("name").String())
+	assert.Equal([]string{"x", "1"}, k.Strings("tags"))
+
+	// Views are point-in-time.
+	require.NoError(t, k.Set("db.host", "remote"))
+	assert.Equal("localhost", db.Get("host").String())
+	assert.Equal("remote", k.View("db").Get("host").String())
+}
+
+func TestViewCaseInsensitive(t *testing.T) {
+	k := koanf.NewWithConf(koanf.Conf{Delim: delim, CaseInsensitive: true})
+	require.NoError(t, k.Load(confmap.Provider(map[string]any{"DB.Host": "localhost"}, delim), nil))
+
+	assert.Equal(t, "localhost", k.View("db.host").String())
+	assert.Equal(t, "localhost", k.View("db").Get("HOST").String())
+	assert.Equal(t, "DB.Host", k.View("db").Get("host").Path())
+}
+
+func BenchmarkView(b *testing.B) {
+	mp := make(map[string]any)
+	for i := 0; i < 5000; i++ {
+		mp[fmt.Sprintf("services.svc%d.port", i)] = i
+	}
+	k := koanf.New(delim)
+	require.NoError(b, k.Load(confmap.Provider(mp, delim), nil))
+
+	b.Run("Get", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			_ = k.Get("services").(map[string]any)["svc1"]
+		}
+	})
+	b.Run("View", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			_ = k.View("services").Get("svc1.port").Int()
+		}
+	})
+}
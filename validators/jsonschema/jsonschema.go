@@ -0,0 +1,141 @@
+// Package jsonschema implements a koanf.Validator that validates
+// conf maps against a JSON Schema (draft 2020-12 unless the schema's
+// $schema says otherwise).
+package jsonschema
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/santhosh-tekuri/jsonschema/v6"
+	"github.com/santhosh-tekuri/jsonschema/v6/kind"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+)
+
+// schemaURL is the URL under which the schema is registered with the compiler.
+const schemaURL = "koanf://schema.json"
+
+// JSONSchema implements a JSON Schema validator.
+type JSONSchema struct {
+	schema *jsonschema.Schema
+	delim  string
+}
+
+// Violation is a single schema violation at a config key.
+type Violation struct {
+	// Key is the full delimited key path of the offending value, for
+	// instance, `db.port`. It's empty if the violation is at the root.
+	Key string
+
+	// Message describes the violation.
+	Message string
+}
+
+// Error is returned by Validate listing every schema violation.
+type Error struct {
+	Violations []Violation
+}
+
+// Error returns all violations as a single string.
+func (e *Error) Error() string {
+	s := make([]string, 0, len(e.Violations))
+	for _, v := range e.Violations {
+		if v.Key == "" {
+			s = append(s, v.Message)
+			continue
+		}
+		s = append(s, v.Key+": "+v.Message)
+	}
+	return "config does not match schema: " + strings.Join(s, "; ")
+}
+
+// printer formats the library's error messages.
+var printer = message.NewPrinter(language.English)
+
+// Validator compiles the given JSON Schema document and returns a validator.
+// delim is the koanf key path delimiter used to report the keys in errors.
+func Validator(schema []byte, delim string) (*JSONSchema, error) {
+	doc, err := jsonschema.UnmarshalJSON(bytes.NewReader(schema))
+	if err != nil {
+		return nil, fmt.Errorf("error parsing schema: %w", err)
+	}
+
+	c := jsonschema.NewCompiler()
+	c.DefaultDraft(jsonschema.Draft2020)
+	if err := c.AddResource(schemaURL, doc); err != nil {
+		return nil, fmt.Errorf("error loading schema: %w", err)
+	}
+
+	sch, err := c.Compile(schemaURL)
+	if err != nil {
+		return nil, fmt.Errorf("error compiling schema: %w", err)
+	}
+
+	return &JSONSchema{schema: sch, delim: delim}, nil
+}
+
+// Validate validates the given conf map against the schema. If it is
+// invalid, an *Error listing every violation is returned.
+func (j *JSONSchema) Validate(mp map[string]any) error {
+	// Conf maps can contain arbitrary Go types (eg: []string, time.Duration, structs).
+	// Normalize them to JSON types that the schema validator understands.
+	b, err := json.Marshal(mp)
+	if err != nil {
+		return fmt.Errorf("error encoding config for validation: %w", err)
+	}
+	doc, err := jsonschema.UnmarshalJSON(bytes.NewReader(b))
+	if err != nil {
+		return fmt.Errorf("error encoding config for validation: %w", err)
+	}
+
+	err = j.schema.Validate(doc)
+	if err == nil {
+		return nil
+	}
+
+	var ve *jsonschema.ValidationError
+	if !errors.As(err, &ve) {
+		return err
+	}
+
+	out := &Error{}
+	j.collect(ve, out)
+	sort.SliceStable(out.Violations, func(a, b int) bool {
+		return out.Violations[a].Key < out.Violations[b].Key
+	})
+	return out
+}
+
+// collect recursively collects the leaf errors of a validation error as violations.
+func (j *JSONSchema) collect(ve *jsonschema.ValidationError, out *Error) {
+	if len(ve.Causes) > 0 {
+		for _, c := range ve.Causes {
+			j.collect(c, out)
+		}
+		return
+	}
+
+	key := strings.Join(ve.InstanceLocation, j.delim)
+
+	// Report missing required properties at their own key paths.
+	if r, ok := ve.ErrorKind.(*kind.Required); ok {
+		for _, m := range r.Missing {
+			k := m
+			if key != "" {
+				k = key + j.delim + m
+			}
+			out.Violations = append(out.Violations, Violation{Key: k, Message: "is required"})
+		}
+		return
+	}
+
+	out.Violations = append(out.Violations, Violation{
+		Key:     key,
+		Message: ve.ErrorKind.LocalizedString(printer),
+	})
+}
@@ -0,0 +1,106 @@
+package jsonschema
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testSchema = []byte(`{
+	"$schema": "https://json-schema.org/draft/2020-12/schema",
+	"type": "object",
+	"required": ["db"],
+	"properties": {
+		"db": {
+			"type": "object",
+			"required": ["host", "port"],
+			"properties": {
+				"host": {"type": "string", "minLength": 1},
+				"port": {"type": "integer", "minimum": 1, "maximum": 65535},
+				"timeout": {"type": "integer"}
+			}
+		},
+		"tags": {
+			"type": "array",
+			"items": {"type": "string"}
+		}
+	}
+}`)
+
+func TestValidator(t *testing.T) {
+	_, err := Validator([]byte(`{bad`), ".")
+	assert.Error(t, err)
+
+	_, err = Validator([]byte(`{"type": "nope"}`), ".")
+	assert.Error(t, err)
+
+	v, err := Validator(testSchema, ".")
+	require.NoError(t, err)
+	assert.NotNil(t, v)
+}
+
+func TestValidate(t *testing.T) {
+	v, err := Validator(testSchema, "/")
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name       string
+		input      map[string]any
+		violations []Violation
+	}{
+		{
+			name: "Valid",
+			input: map[string]any{
+				"db": map[string]any{
+					"host":    "localhost",
+					"port":    5432,
+					"timeout": time.Second,
+				},
+				"tags": []string{"a", "b"},
+			},
+		},
+		{
+			name:       "Missing root key",
+			input:      map[string]any{},
+			violations: []Violation{{Key: "db", Message: "is required"}},
+		},
+		{
+			name: "Multiple violations",
+			input: map[string]any{
+				"db": map[string]any{
+					"port": 70000,
+				},
+				"tags": []any{"a", 1},
+			},
+			violations: []Violation{
+				{Key: "db/host", Message: "is required"},
+				{Key: "db/port", Message: "maximum: got 70,000, want 65,535"},
+				{Key: "tags/1", Message: "got number, want string"},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := v.Validate(tc.input)
+			if tc.violations == nil {
+				assert.NoError(t, err)
+				return
+			}
+
+			var ve *Error
+			require.True(t, errors.As(err, &ve), "unexpected error: %v", err)
+			assert.Equal(t, tc.violations, ve.Violations)
+			assert.Contains(t, err.Error(), tc.violations[0].Key)
+		})
+	}
+}
+
+func TestValidateUnencodable(t *testing.T) {
+	v, err := Validator(testSchema, ".")
+	require.NoError(t, err)
+	assert.Error(t, v.Validate(map[string]any{"ch": make(chan int)}))
+}
@@ -0,0 +1,303 @@
+package koanf
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/knadh/koanf/maps"
+)
+
+// View is a read-only view of a node in the config tree (a map, a slice, or
+// a value) returned by View(). Unlike Get(), it references the config without
+// copying it, so large subtrees can be traversed cheaply. It never exposes the
+// underlying maps or slices, and Value() returns copies of them, so the config
+// can't be modified through a View.
+//
+// A View is a point-in-time view of the config (see Snapshot()). It doesn't
+// reflect the changes made after it was created. The zero View is a missing node.
+type View struct {
+	val  any
+	path string
+	ok   bool
+
+	delim string
+	fold  bool
+
+	// The instance and the state that the node is from, and whether it's
+	// the result of Eval(), for Koanf().
+	ko   *Koanf
+	st   *state
+	eval bool
+}
+
+// View returns a read-only View of the node at the given key path. If the
+// key path is `""`, it's the root of the config. If the key path does not
+// exist, the View is missing (see View.Exists()).
+func (ko *Koanf) View(path string) View {
+	st := ko.st.Load()
+	v := View{delim: ko.conf.Delim, fold: ko.conf.CaseInsensitive, ko: ko, st: st}
+	if path == "" {
+		v.val, v.ok = st.confMap, true
+		return v
+	}
+
+	path = ko.key(st, path)
+	p, ok := ko.lookup(st, path)
+	if !ok {
+		return v
+	}
+
+	v.val, v.path, v.ok = maps.Search(st.confMap, p), path, true
+	return v
+}
+
+// Exists returns true if the node exists in the config.
+func (v View) Exists() bool {
+	return v.ok
+}
+
+// Path returns the full key path of the node.
+func (v View) Path() string {
+	return v.path
+}
+
+// IsMap returns true if the node is a map.
+func (v View) IsMap() bool {
+	_, ok := v.val.(map[string]any)
+	return ok
+}
+
+// IsSlice returns true if the node is a slice.
+func (v View) IsSlice() bool {
+	_, ok := v.val.([]any)
+	return ok
+}
+
+// Get returns the View of the node at the given key path relative to the node.
+// Elements of slices are addressed by their index, eg: `servers.0.host`
+// or `servers[0].host`.
+func (v View) Get(path string) View {
+	if path == "" {
+		return v
+	}
+	if strings.Contains(path, "[") {
+		path = normalizeIndexes(path, v.delim)
+	}
+
+	out := v
+	for _, k := range maps.SplitKey(path, v.delim) {
+		switch val := out.val.(type) {
+		case map[string]any:
+			out = out.child(val, k)
+		case []any:
+			i, ok := maps.Index(val, k)
+			if !ok {
+				return v.missing()
+			}
+			out = out.Index(i)
+		default:
+			return v.missing()
+		}
+		if !out.ok {
+			return out
+		}
+	}
+	return out
+}
+
+// missing returns a missing View from the same config as the node.
+func (v View) missing() View {
+	return View{delim: v.delim, fold: v.fold, ko: v.ko, st: v.st, eval: v.eval}
+}
+
+// child returns the View of the key in the node's map.
+func (v View) child(mp map[string]any, key string) View {
+	out := v.missing()
+
+	val, ok := mp[key]
+	if !ok && v.fold {
+		for k, c := range mp {
+			if strings.EqualFold(k, key) {
+				key, val, ok = k, c, true
+				break
+			}
+		}
+	}
+	if !ok {
+		return out
+	}
+
+	out.val, out.path, out.ok = val, joinKey(v.path, maps.QuoteKey(key, v.delim), v.delim), true
+	return out
+}
+
+// Len returns the number of keys in a map node or elements in a slice node,
+// or 0 for other nodes.
+func (v View) Len() int {
+	switch val := v.val.(type) {
+	case map[string]any:
+		return len(val)
+	case []any:
+		return len(val)
+	}
+	return 0
+}
+
+// Keys returns the sorted keys of a map node, or an empty slice
+// for other nodes.
+func (v View) Keys() []string {
+	mp, ok := v.val.(map[string]any)
+	if !ok {
+		return []string{}
+	}
+
+	out := make([]string, 0, len(mp))
+	for k := range mp {
+		out = append(out, k)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// Index returns the View of the element at index i of a slice node.
+// The View is missing if the node isn't a slice or i is out of range.
+func (v View) Index(i int) View {
+	out := v.missing()
+
+	sl, ok := v.val.([]any)
+	if !ok || i < 0 || i >= len(sl) {
+		return out
+	}
+
+	out.val, out.path, out.ok = sl[i], joinKey(v.path, strconv.Itoa(i), v.delim), true
+	return out
+}
+
+// Range calls fn for every key in a map node in sorted order, or every
+// element in a slice node with its index as the key, until fn returns false.
+func (v View) Range(fn func(key string, v View) bool) {
+	switch val := v.val.(type) {
+	case map[string]any:
+		for _, k := range v.Keys() {
+			if !fn(k, v.child(val, k)) {
+				return
+			}
+		}
+	case []any:
+		for i := range val {
+			if !fn(strconv.Itoa(i), v.Index(i)) {
+				return
+			}
+		}
+	}
+}
+
+// Koanf returns a new Koanf instance with a copy of a map node loaded, or an
+// empty instance if the node isn't a map. Like Cut(), the instance has the
+// Conf of the instance that the View is from, and the keys under the node
+// remain sensitive. As the key paths of the results of Eval() aren't known,
+// the keys in them whose string values are the values of sensitive keys are
+// sensitive.
+func (v View) Koanf() *Koanf {
+	if v.ko == nil {
+		return NewWithConf(Conf{Delim: v.delim, CaseInsensitive: v.fold})
+	}
+
+	mp, ok := v.val.(map[string]any)
+	if !ok {
+		mp = make(map[string]any)
+	}
+	if v.eval {
+		return v.ko.derive(v.st, maps.Copy(mp), "Eval", v.ko.sensitiveValues(v.st))
+	}
+	return v.ko.derive(v.st, maps.Copy(mp), "View", v.ko.sensitiveUnder(v.st, v.path))
+}
+
+// Value returns the value of the node. Maps and slices are
+// copied like Get() does.
+func (v View) Value() any {
+	return copyValue(v.val)
+}
+
+// String returns the string value of the node or "" if it's missing.
+func (v View) String() string {
+	switch val := v.val.(type) {
+	case nil:
+		return ""
+	case string:
+		return val
+	}
+	return fmt.Sprintf("%v", v.val)
+}
+
+// Int64 returns the int64 value of the node or 0 if it's missing
+// or isn't a valid int64.
+func (v View) Int64() int64 {
+	if v.val == nil {
+		return 0
+	}
+	i, _ := toInt64(v.val)
+	return i
+}
+
+// Int returns the int value of the node or 0 if it's missing
+// or isn't a valid int.
+func (v View) Int() int {
+	return int(v.Int64())
+}
+
+// Float64 returns the float64 value of the node or 0 if it's missing
+// or isn't a valid float64.
+func (v View) Float64() float64 {
+	if v.val == nil {
+		return 0
+	}
+	f, _ := toFloat64(v.val)
+	return f
+}
+
+// Bool returns the bool value of the node or false if it's missing
+// or isn't a valid bool representation.
+func (v View) Bool() bool {
+	if v.val == nil {
+		return false
+	}
+	b, _ := toBool(v.val)
+	return b
+}
+
+// Duration returns the time.Duration value of the node, which
+// is either numeric or a string parsable by time.ParseDuration.
+func (v View) Duration() time.Duration {
+	if i := v.Int64(); i != 0 {
+		return time.Duration(i)
+	}
+
+	d, _ := time.ParseDuration(v.String())
+	return d
+}
+
+// Strings returns the []string value of a slice node or an
+// empty slice if it's missing or isn't a slice.
+func (v View) Strings() []string {
+	switch val := v.val.(type) {
+	case []any:
+		out := make([]string, 0, len(val))
+		for _, u := range val {
+			if s, ok := u.(string); ok {
+				out = append(out, s)
+			} else {
+				out = append(out, fmt.Sprintf("%v", u))
+			}
+		}
+		return out
+	case []string:
+		out := make([]string, len(val))
+		copy(out, val)
+		return out
+	}
+	return []string{}
+}